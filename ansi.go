@@ -0,0 +1,147 @@
+// -*- coding: utf-8 -*-
+// ansi.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 05:02:44.610537281 (1792213364)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following constants are the 16 colors of the basic ANSI palette. The
+// first eight are shown with the codes 30-37 (foreground) and 40-47
+// (background), and the bright ones with the codes 90-97 and 100-107. Their
+// actual appearance depends on the terminal
+const (
+	AnsiBlack Ansi16 = iota
+	AnsiRed
+	AnsiGreen
+	AnsiYellow
+	AnsiBlue
+	AnsiMagenta
+	AnsiCyan
+	AnsiWhite
+	AnsiBrightBlack
+	AnsiBrightRed
+	AnsiBrightGreen
+	AnsiBrightYellow
+	AnsiBrightBlue
+	AnsiBrightMagenta
+	AnsiBrightCyan
+	AnsiBrightWhite
+)
+
+// The following constants define the codes used for showing the colors of the
+// ANSI palettes
+const (
+	ansi16_foreground        = 30
+	ansi16_background        = 40
+	ansi16_bright_foreground = 90
+	ansi16_bright_background = 100
+	ansi256_foreground       = "38;5"
+	ansi256_background       = "48;5"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A color of the basic ANSI palette with 16 colors, which is supported by
+// almost every terminal. When given to a color verb, it sets the foreground
+// color
+type Ansi16 uint8
+
+// A color of the extended ANSI palette with 256 colors: the first 16 are the
+// colors of the basic palette, followed by a 6x6x6 color cube and 24 shades
+// of gray. When given to a color verb, it sets the foreground color
+type Ansi256 uint8
+
+// The following type defines a combination of foreground, background colors and
+// properties, where colors are taken from the basic ANSI palette. If no
+// background color is given, it is left unchanged
+type EffectAnsi16 struct {
+	Fg         Ansi16
+	Bg         *Ansi16
+	Properties uint16
+}
+
+// The following type defines a combination of foreground, background colors and
+// properties, where colors are taken from the extended ANSI palette. If no
+// background color is given, it is left unchanged
+type EffectAnsi256 struct {
+	Fg         Ansi256
+	Bg         *Ansi256
+	Properties uint16
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The colors of the basic ANSI palette as shown by xterm
+var ansi16Palette = [16]Color{
+	{R: 0x00, G: 0x00, B: 0x00},
+	{R: 0xcd, G: 0x00, B: 0x00},
+	{R: 0x00, G: 0xcd, B: 0x00},
+	{R: 0xcd, G: 0xcd, B: 0x00},
+	{R: 0x00, G: 0x00, B: 0xee},
+	{R: 0xcd, G: 0x00, B: 0xcd},
+	{R: 0x00, G: 0xcd, B: 0xcd},
+	{R: 0xe5, G: 0xe5, B: 0xe5},
+	{R: 0x7f, G: 0x7f, B: 0x7f},
+	{R: 0xff, G: 0x00, B: 0x00},
+	{R: 0x00, G: 0xff, B: 0x00},
+	{R: 0xff, G: 0xff, B: 0x00},
+	{R: 0x5c, G: 0x5c, B: 0xff},
+	{R: 0xff, G: 0x00, B: 0xff},
+	{R: 0x00, G: 0xff, B: 0xff},
+	{R: 0xff, G: 0xff, B: 0xff},
+}
+
+// Levels of every primary color in the color cube of the extended ANSI palette
+var ansi256Levels = [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+
+// Names of the colors of the basic ANSI palette which can be used in textual
+// color specifications, as in git. Colors without the prefix "bright" are
+// CSS named colors in textual color specifications
+var ansi16Names = map[string]Ansi16{
+	"brightblack":   AnsiBrightBlack,
+	"brightred":     AnsiBrightRed,
+	"brightgreen":   AnsiBrightGreen,
+	"brightyellow":  AnsiBrightYellow,
+	"brightblue":    AnsiBrightBlue,
+	"brightmagenta": AnsiBrightMagenta,
+	"brightcyan":    AnsiBrightCyan,
+	"brightwhite":   AnsiBrightWhite,
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the RGB color used by xterm for showing this color. Other terminals
+// might show it differently
+func (c Ansi16) Color() Color {
+	return ansi16Palette[c&0xf]
+}
+
+// Return the RGB color used by xterm for showing this color. Other terminals
+// might show the first 16 colors differently
+func (c Ansi256) Color() Color {
+
+	switch {
+	case c < 16:
+		return ansi16Palette[c]
+	case c < 232:
+		idx := c - 16
+		return Color{R: ansi256Levels[idx/36], G: ansi256Levels[(idx/6)%6], B: ansi256Levels[idx%6]}
+	default:
+		gray := 8 + 10*uint8(c-232)
+		return Color{R: gray, G: gray, B: gray}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -8,43 +8,45 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/clinaresl/golor"
 	"github.com/clinaresl/golor/utils"
 )
 
-// Print the given string with a pleasant foreground gradient from the start
-// combination of red, green and blue until the specified end
-func fadeInForeground(str string, start, end uint32) {
+// Print every character of the given string with the effect returned by the
+// given function. The whole string is printed at once, so that only the
+// attributes which change from one character to the next are issued
+func printEffects(str string, start, end uint32, effect func(val uint32) any) {
 
+	var format strings.Builder
+	args := make([]any, 0, 2*len(str))
 	for idx, val := range utils.HslGradient(start, end, len(str)) {
-		golor.Printf("%C{%c}", val, str[idx])
+		format.WriteString("%C{%c}")
+		args = append(args, effect(val), str[idx])
 	}
+	format.WriteString("\n")
+
+	golor.Printf(format.String(), args...)
+}
 
-	golor.Printf("\n")
+// Print the given string with a pleasant foreground gradient from the start
+// combination of red, green and blue until the specified end
+func fadeInForeground(str string, start, end uint32) {
+	printEffects(str, start, end, func(val uint32) any { return val })
 }
 
 // Print the given string with a pleasant background gradient from the start
 // combination of red, green and blue until the specified end.
 func fadeInBackground(str string, start, end uint32) {
-
-	for idx, val := range utils.HslGradient(start, end, len(str)) {
-		golor.Printf("%C{%c}", uint64(val)<<24, str[idx])
-	}
-
-	golor.Printf("\n")
+	printEffects(str, start, end, func(val uint32) any { return uint64(val) << 24 })
 }
 
 // Print the given string with a pleasant gradient from the start combination of
 // red, green and blue until the specified end. The gradient is computed for the
 // foreground and the background is the opposite
 func fadeInForegroundBackground(str string, start, end uint32) {
-
-	for idx, val := range utils.HslGradient(start, end, len(str)) {
-		golor.Printf("%C{%c}", uint64(val^0x00ffffff)<<24|uint64(val), str[idx])
-	}
-
-	golor.Printf("\n")
+	printEffects(str, start, end, func(val uint32) any { return uint64(val^0x00ffffff)<<24 | uint64(val) })
 }
 
 func main() {
@@ -0,0 +1,605 @@
+// -*- coding: utf-8 -*-
+// colors.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 04:12:37.481906253 (1792210357)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/clinaresl/golor/utils"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// Name of the color used by default by the terminal in textual notations
+const default_color_name = "default"
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A named color, as defined in CSS
+type namedColor struct {
+	name  string
+	color Color
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The following colors are all the named colors defined in CSS (CSS Color
+// Module Level 4), which also include the X11 colors. Note that some of them
+// are aliases of others, e.g., Aqua and Cyan, or Gray and Grey
+var (
+	AliceBlue            = Color{R: 0xf0, G: 0xf8, B: 0xff}
+	AntiqueWhite         = Color{R: 0xfa, G: 0xeb, B: 0xd7}
+	Aqua                 = Color{R: 0x00, G: 0xff, B: 0xff}
+	Aquamarine           = Color{R: 0x7f, G: 0xff, B: 0xd4}
+	Azure                = Color{R: 0xf0, G: 0xff, B: 0xff}
+	Beige                = Color{R: 0xf5, G: 0xf5, B: 0xdc}
+	Bisque               = Color{R: 0xff, G: 0xe4, B: 0xc4}
+	Black                = Color{R: 0x00, G: 0x00, B: 0x00}
+	BlanchedAlmond       = Color{R: 0xff, G: 0xeb, B: 0xcd}
+	Blue                 = Color{R: 0x00, G: 0x00, B: 0xff}
+	BlueViolet           = Color{R: 0x8a, G: 0x2b, B: 0xe2}
+	Brown                = Color{R: 0xa5, G: 0x2a, B: 0x2a}
+	BurlyWood            = Color{R: 0xde, G: 0xb8, B: 0x87}
+	CadetBlue            = Color{R: 0x5f, G: 0x9e, B: 0xa0}
+	Chartreuse           = Color{R: 0x7f, G: 0xff, B: 0x00}
+	Chocolate            = Color{R: 0xd2, G: 0x69, B: 0x1e}
+	Coral                = Color{R: 0xff, G: 0x7f, B: 0x50}
+	CornflowerBlue       = Color{R: 0x64, G: 0x95, B: 0xed}
+	Cornsilk             = Color{R: 0xff, G: 0xf8, B: 0xdc}
+	Crimson              = Color{R: 0xdc, G: 0x14, B: 0x3c}
+	Cyan                 = Color{R: 0x00, G: 0xff, B: 0xff}
+	DarkBlue             = Color{R: 0x00, G: 0x00, B: 0x8b}
+	DarkCyan             = Color{R: 0x00, G: 0x8b, B: 0x8b}
+	DarkGoldenrod        = Color{R: 0xb8, G: 0x86, B: 0x0b}
+	DarkGray             = Color{R: 0xa9, G: 0xa9, B: 0xa9}
+	DarkGreen            = Color{R: 0x00, G: 0x64, B: 0x00}
+	DarkGrey             = Color{R: 0xa9, G: 0xa9, B: 0xa9}
+	DarkKhaki            = Color{R: 0xbd, G: 0xb7, B: 0x6b}
+	DarkMagenta          = Color{R: 0x8b, G: 0x00, B: 0x8b}
+	DarkOliveGreen       = Color{R: 0x55, G: 0x6b, B: 0x2f}
+	DarkOrange           = Color{R: 0xff, G: 0x8c, B: 0x00}
+	DarkOrchid           = Color{R: 0x99, G: 0x32, B: 0xcc}
+	DarkRed              = Color{R: 0x8b, G: 0x00, B: 0x00}
+	DarkSalmon           = Color{R: 0xe9, G: 0x96, B: 0x7a}
+	DarkSeaGreen         = Color{R: 0x8f, G: 0xbc, B: 0x8f}
+	DarkSlateBlue        = Color{R: 0x48, G: 0x3d, B: 0x8b}
+	DarkSlateGray        = Color{R: 0x2f, G: 0x4f, B: 0x4f}
+	DarkSlateGrey        = Color{R: 0x2f, G: 0x4f, B: 0x4f}
+	DarkTurquoise        = Color{R: 0x00, G: 0xce, B: 0xd1}
+	DarkViolet           = Color{R: 0x94, G: 0x00, B: 0xd3}
+	DeepPink             = Color{R: 0xff, G: 0x14, B: 0x93}
+	DeepSkyBlue          = Color{R: 0x00, G: 0xbf, B: 0xff}
+	DimGray              = Color{R: 0x69, G: 0x69, B: 0x69}
+	DimGrey              = Color{R: 0x69, G: 0x69, B: 0x69}
+	DodgerBlue           = Color{R: 0x1e, G: 0x90, B: 0xff}
+	Firebrick            = Color{R: 0xb2, G: 0x22, B: 0x22}
+	FloralWhite          = Color{R: 0xff, G: 0xfa, B: 0xf0}
+	ForestGreen          = Color{R: 0x22, G: 0x8b, B: 0x22}
+	Fuchsia              = Color{R: 0xff, G: 0x00, B: 0xff}
+	Gainsboro            = Color{R: 0xdc, G: 0xdc, B: 0xdc}
+	GhostWhite           = Color{R: 0xf8, G: 0xf8, B: 0xff}
+	Gold                 = Color{R: 0xff, G: 0xd7, B: 0x00}
+	Goldenrod            = Color{R: 0xda, G: 0xa5, B: 0x20}
+	Gray                 = Color{R: 0x80, G: 0x80, B: 0x80}
+	Green                = Color{R: 0x00, G: 0x80, B: 0x00}
+	GreenYellow          = Color{R: 0xad, G: 0xff, B: 0x2f}
+	Grey                 = Color{R: 0x80, G: 0x80, B: 0x80}
+	Honeydew             = Color{R: 0xf0, G: 0xff, B: 0xf0}
+	HotPink              = Color{R: 0xff, G: 0x69, B: 0xb4}
+	IndianRed            = Color{R: 0xcd, G: 0x5c, B: 0x5c}
+	Indigo               = Color{R: 0x4b, G: 0x00, B: 0x82}
+	Ivory                = Color{R: 0xff, G: 0xff, B: 0xf0}
+	Khaki                = Color{R: 0xf0, G: 0xe6, B: 0x8c}
+	Lavender             = Color{R: 0xe6, G: 0xe6, B: 0xfa}
+	LavenderBlush        = Color{R: 0xff, G: 0xf0, B: 0xf5}
+	LawnGreen            = Color{R: 0x7c, G: 0xfc, B: 0x00}
+	LemonChiffon         = Color{R: 0xff, G: 0xfa, B: 0xcd}
+	LightBlue            = Color{R: 0xad, G: 0xd8, B: 0xe6}
+	LightCoral           = Color{R: 0xf0, G: 0x80, B: 0x80}
+	LightCyan            = Color{R: 0xe0, G: 0xff, B: 0xff}
+	LightGoldenrodYellow = Color{R: 0xfa, G: 0xfa, B: 0xd2}
+	LightGray            = Color{R: 0xd3, G: 0xd3, B: 0xd3}
+	LightGreen           = Color{R: 0x90, G: 0xee, B: 0x90}
+	LightGrey            = Color{R: 0xd3, G: 0xd3, B: 0xd3}
+	LightPink            = Color{R: 0xff, G: 0xb6, B: 0xc1}
+	LightSalmon          = Color{R: 0xff, G: 0xa0, B: 0x7a}
+	LightSeaGreen        = Color{R: 0x20, G: 0xb2, B: 0xaa}
+	LightSkyBlue         = Color{R: 0x87, G: 0xce, B: 0xfa}
+	LightSlateGray       = Color{R: 0x77, G: 0x88, B: 0x99}
+	LightSlateGrey       = Color{R: 0x77, G: 0x88, B: 0x99}
+	LightSteelBlue       = Color{R: 0xb0, G: 0xc4, B: 0xde}
+	LightYellow          = Color{R: 0xff, G: 0xff, B: 0xe0}
+	Lime                 = Color{R: 0x00, G: 0xff, B: 0x00}
+	LimeGreen            = Color{R: 0x32, G: 0xcd, B: 0x32}
+	Linen                = Color{R: 0xfa, G: 0xf0, B: 0xe6}
+	Magenta              = Color{R: 0xff, G: 0x00, B: 0xff}
+	Maroon               = Color{R: 0x80, G: 0x00, B: 0x00}
+	MediumAquamarine     = Color{R: 0x66, G: 0xcd, B: 0xaa}
+	MediumBlue           = Color{R: 0x00, G: 0x00, B: 0xcd}
+	MediumOrchid         = Color{R: 0xba, G: 0x55, B: 0xd3}
+	MediumPurple         = Color{R: 0x93, G: 0x70, B: 0xdb}
+	MediumSeaGreen       = Color{R: 0x3c, G: 0xb3, B: 0x71}
+	MediumSlateBlue      = Color{R: 0x7b, G: 0x68, B: 0xee}
+	MediumSpringGreen    = Color{R: 0x00, G: 0xfa, B: 0x9a}
+	MediumTurquoise      = Color{R: 0x48, G: 0xd1, B: 0xcc}
+	MediumVioletRed      = Color{R: 0xc7, G: 0x15, B: 0x85}
+	MidnightBlue         = Color{R: 0x19, G: 0x19, B: 0x70}
+	MintCream            = Color{R: 0xf5, G: 0xff, B: 0xfa}
+	MistyRose            = Color{R: 0xff, G: 0xe4, B: 0xe1}
+	Moccasin             = Color{R: 0xff, G: 0xe4, B: 0xb5}
+	NavajoWhite          = Color{R: 0xff, G: 0xde, B: 0xad}
+	Navy                 = Color{R: 0x00, G: 0x00, B: 0x80}
+	OldLace              = Color{R: 0xfd, G: 0xf5, B: 0xe6}
+	Olive                = Color{R: 0x80, G: 0x80, B: 0x00}
+	OliveDrab            = Color{R: 0x6b, G: 0x8e, B: 0x23}
+	Orange               = Color{R: 0xff, G: 0xa5, B: 0x00}
+	OrangeRed            = Color{R: 0xff, G: 0x45, B: 0x00}
+	Orchid               = Color{R: 0xda, G: 0x70, B: 0xd6}
+	PaleGoldenrod        = Color{R: 0xee, G: 0xe8, B: 0xaa}
+	PaleGreen            = Color{R: 0x98, G: 0xfb, B: 0x98}
+	PaleTurquoise        = Color{R: 0xaf, G: 0xee, B: 0xee}
+	PaleVioletRed        = Color{R: 0xdb, G: 0x70, B: 0x93}
+	PapayaWhip           = Color{R: 0xff, G: 0xef, B: 0xd5}
+	PeachPuff            = Color{R: 0xff, G: 0xda, B: 0xb9}
+	Peru                 = Color{R: 0xcd, G: 0x85, B: 0x3f}
+	Pink                 = Color{R: 0xff, G: 0xc0, B: 0xcb}
+	Plum                 = Color{R: 0xdd, G: 0xa0, B: 0xdd}
+	PowderBlue           = Color{R: 0xb0, G: 0xe0, B: 0xe6}
+	Purple               = Color{R: 0x80, G: 0x00, B: 0x80}
+	RebeccaPurple        = Color{R: 0x66, G: 0x33, B: 0x99}
+	Red                  = Color{R: 0xff, G: 0x00, B: 0x00}
+	RosyBrown            = Color{R: 0xbc, G: 0x8f, B: 0x8f}
+	RoyalBlue            = Color{R: 0x41, G: 0x69, B: 0xe1}
+	SaddleBrown          = Color{R: 0x8b, G: 0x45, B: 0x13}
+	Salmon               = Color{R: 0xfa, G: 0x80, B: 0x72}
+	SandyBrown           = Color{R: 0xf4, G: 0xa4, B: 0x60}
+	SeaGreen             = Color{R: 0x2e, G: 0x8b, B: 0x57}
+	SeaShell             = Color{R: 0xff, G: 0xf5, B: 0xee}
+	Sienna               = Color{R: 0xa0, G: 0x52, B: 0x2d}
+	Silver               = Color{R: 0xc0, G: 0xc0, B: 0xc0}
+	SkyBlue              = Color{R: 0x87, G: 0xce, B: 0xeb}
+	SlateBlue            = Color{R: 0x6a, G: 0x5a, B: 0xcd}
+	SlateGray            = Color{R: 0x70, G: 0x80, B: 0x90}
+	SlateGrey            = Color{R: 0x70, G: 0x80, B: 0x90}
+	Snow                 = Color{R: 0xff, G: 0xfa, B: 0xfa}
+	SpringGreen          = Color{R: 0x00, G: 0xff, B: 0x7f}
+	SteelBlue            = Color{R: 0x46, G: 0x82, B: 0xb4}
+	Tan                  = Color{R: 0xd2, G: 0xb4, B: 0x8c}
+	Teal                 = Color{R: 0x00, G: 0x80, B: 0x80}
+	Thistle              = Color{R: 0xd8, G: 0xbf, B: 0xd8}
+	Tomato               = Color{R: 0xff, G: 0x63, B: 0x47}
+	Turquoise            = Color{R: 0x40, G: 0xe0, B: 0xd0}
+	Violet               = Color{R: 0xee, G: 0x82, B: 0xee}
+	Wheat                = Color{R: 0xf5, G: 0xde, B: 0xb3}
+	White                = Color{R: 0xff, G: 0xff, B: 0xff}
+	WhiteSmoke           = Color{R: 0xf5, G: 0xf5, B: 0xf5}
+	Yellow               = Color{R: 0xff, G: 0xff, B: 0x00}
+	YellowGreen          = Color{R: 0x9a, G: 0xcd, B: 0x32}
+)
+
+// Table of all named colors sorted by name
+var namedColors = []namedColor{
+	{"aliceblue", AliceBlue},
+	{"antiquewhite", AntiqueWhite},
+	{"aqua", Aqua},
+	{"aquamarine", Aquamarine},
+	{"azure", Azure},
+	{"beige", Beige},
+	{"bisque", Bisque},
+	{"black", Black},
+	{"blanchedalmond", BlanchedAlmond},
+	{"blue", Blue},
+	{"blueviolet", BlueViolet},
+	{"brown", Brown},
+	{"burlywood", BurlyWood},
+	{"cadetblue", CadetBlue},
+	{"chartreuse", Chartreuse},
+	{"chocolate", Chocolate},
+	{"coral", Coral},
+	{"cornflowerblue", CornflowerBlue},
+	{"cornsilk", Cornsilk},
+	{"crimson", Crimson},
+	{"cyan", Cyan},
+	{"darkblue", DarkBlue},
+	{"darkcyan", DarkCyan},
+	{"darkgoldenrod", DarkGoldenrod},
+	{"darkgray", DarkGray},
+	{"darkgreen", DarkGreen},
+	{"darkgrey", DarkGrey},
+	{"darkkhaki", DarkKhaki},
+	{"darkmagenta", DarkMagenta},
+	{"darkolivegreen", DarkOliveGreen},
+	{"darkorange", DarkOrange},
+	{"darkorchid", DarkOrchid},
+	{"darkred", DarkRed},
+	{"darksalmon", DarkSalmon},
+	{"darkseagreen", DarkSeaGreen},
+	{"darkslateblue", DarkSlateBlue},
+	{"darkslategray", DarkSlateGray},
+	{"darkslategrey", DarkSlateGrey},
+	{"darkturquoise", DarkTurquoise},
+	{"darkviolet", DarkViolet},
+	{"deeppink", DeepPink},
+	{"deepskyblue", DeepSkyBlue},
+	{"dimgray", DimGray},
+	{"dimgrey", DimGrey},
+	{"dodgerblue", DodgerBlue},
+	{"firebrick", Firebrick},
+	{"floralwhite", FloralWhite},
+	{"forestgreen", ForestGreen},
+	{"fuchsia", Fuchsia},
+	{"gainsboro", Gainsboro},
+	{"ghostwhite", GhostWhite},
+	{"gold", Gold},
+	{"goldenrod", Goldenrod},
+	{"gray", Gray},
+	{"green", Green},
+	{"greenyellow", GreenYellow},
+	{"grey", Grey},
+	{"honeydew", Honeydew},
+	{"hotpink", HotPink},
+	{"indianred", IndianRed},
+	{"indigo", Indigo},
+	{"ivory", Ivory},
+	{"khaki", Khaki},
+	{"lavender", Lavender},
+	{"lavenderblush", LavenderBlush},
+	{"lawngreen", LawnGreen},
+	{"lemonchiffon", LemonChiffon},
+	{"lightblue", LightBlue},
+	{"lightcoral", LightCoral},
+	{"lightcyan", LightCyan},
+	{"lightgoldenrodyellow", LightGoldenrodYellow},
+	{"lightgray", LightGray},
+	{"lightgreen", LightGreen},
+	{"lightgrey", LightGrey},
+	{"lightpink", LightPink},
+	{"lightsalmon", LightSalmon},
+	{"lightseagreen", LightSeaGreen},
+	{"lightskyblue", LightSkyBlue},
+	{"lightslategray", LightSlateGray},
+	{"lightslategrey", LightSlateGrey},
+	{"lightsteelblue", LightSteelBlue},
+	{"lightyellow", LightYellow},
+	{"lime", Lime},
+	{"limegreen", LimeGreen},
+	{"linen", Linen},
+	{"magenta", Magenta},
+	{"maroon", Maroon},
+	{"mediumaquamarine", MediumAquamarine},
+	{"mediumblue", MediumBlue},
+	{"mediumorchid", MediumOrchid},
+	{"mediumpurple", MediumPurple},
+	{"mediumseagreen", MediumSeaGreen},
+	{"mediumslateblue", MediumSlateBlue},
+	{"mediumspringgreen", MediumSpringGreen},
+	{"mediumturquoise", MediumTurquoise},
+	{"mediumvioletred", MediumVioletRed},
+	{"midnightblue", MidnightBlue},
+	{"mintcream", MintCream},
+	{"mistyrose", MistyRose},
+	{"moccasin", Moccasin},
+	{"navajowhite", NavajoWhite},
+	{"navy", Navy},
+	{"oldlace", OldLace},
+	{"olive", Olive},
+	{"olivedrab", OliveDrab},
+	{"orange", Orange},
+	{"orangered", OrangeRed},
+	{"orchid", Orchid},
+	{"palegoldenrod", PaleGoldenrod},
+	{"palegreen", PaleGreen},
+	{"paleturquoise", PaleTurquoise},
+	{"palevioletred", PaleVioletRed},
+	{"papayawhip", PapayaWhip},
+	{"peachpuff", PeachPuff},
+	{"peru", Peru},
+	{"pink", Pink},
+	{"plum", Plum},
+	{"powderblue", PowderBlue},
+	{"purple", Purple},
+	{"rebeccapurple", RebeccaPurple},
+	{"red", Red},
+	{"rosybrown", RosyBrown},
+	{"royalblue", RoyalBlue},
+	{"saddlebrown", SaddleBrown},
+	{"salmon", Salmon},
+	{"sandybrown", SandyBrown},
+	{"seagreen", SeaGreen},
+	{"seashell", SeaShell},
+	{"sienna", Sienna},
+	{"silver", Silver},
+	{"skyblue", SkyBlue},
+	{"slateblue", SlateBlue},
+	{"slategray", SlateGray},
+	{"slategrey", SlateGrey},
+	{"snow", Snow},
+	{"springgreen", SpringGreen},
+	{"steelblue", SteelBlue},
+	{"tan", Tan},
+	{"teal", Teal},
+	{"thistle", Thistle},
+	{"tomato", Tomato},
+	{"turquoise", Turquoise},
+	{"violet", Violet},
+	{"wheat", Wheat},
+	{"white", White},
+	{"whitesmoke", WhiteSmoke},
+	{"yellow", Yellow},
+	{"yellowgreen", YellowGreen},
+}
+
+// The coordinates in the Oklab color space of all named colors, in the same
+// order, which are computed only once and only if needed
+var namedColorsOklab = sync.OnceValue(func() [][3]float64 {
+
+	coords := make([][3]float64, len(namedColors))
+	for idx, named := range namedColors {
+		coords[idx][0], coords[idx][1], coords[idx][2] = utils.RgbToOklab(named.color.R, named.color.G, named.color.B)
+	}
+	return coords
+})
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Named returns the CSS named color with the given name, e.g., "tomato", and
+// whether it exists at all. Names are not case sensitive
+func Named(name string) (Color, bool) {
+
+	idx, ok := slices.BinarySearchFunc(namedColors, strings.ToLower(name), func(named namedColor, name string) int {
+		return strings.Compare(named.name, name)
+	})
+	if !ok {
+		return Color{}, false
+	}
+
+	return namedColors[idx].color, true
+}
+
+// NearestName returns the name of the CSS named color which is perceptually
+// closest to the given one, i.e., whose euclidean distance in the Oklab color
+// space is the smallest. If the color has several names, e.g., aqua and cyan,
+// the first one in alphabetical order is returned
+func NearestName(c Color) string {
+
+	l, a, b := utils.RgbToOklab(c.R, c.G, c.B)
+	best, bestDist := 0, math.Inf(1)
+	for idx, coords := range namedColorsOklab() {
+		dl, da, db := coords[0]-l, coords[1]-a, coords[2]-b
+		if dist := dl*dl + da*da + db*db; dist < bestDist {
+			best, bestDist = idx, dist
+		}
+	}
+
+	return namedColors[best].name
+}
+
+// Return the numerical value of the given component of a color written in a
+// functional notation, e.g., "50%" or "0.5". Percentages are scaled so that
+// 100% is the given value. If percent is true, plain numbers are taken as
+// percentages as well
+func parseComponent(s string, scale float64, percent bool) (float64, bool) {
+
+	isPercent := strings.HasSuffix(s, "%")
+	val, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, false
+	}
+	if isPercent || percent {
+		val = val * scale / 100
+	}
+
+	return val, true
+}
+
+// Return the hue, in degrees in the range [0, 360), of the given component of a
+// color written in a functional notation. It might be a plain number, which is
+// taken in degrees, or given with any of the units deg, grad, rad or turn
+func parseHue(s string) (float64, bool) {
+
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"deg", 1}, {"grad", 360.0 / 400}, {"rad", 180 / math.Pi}, {"turn", 360},
+	}
+
+	scale := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, scale = strings.TrimSuffix(s, unit.suffix), unit.scale
+			break
+		}
+	}
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, false
+	}
+	val = math.Mod(val*scale, 360)
+	if val < 0 {
+		val += 360
+	}
+
+	return val, true
+}
+
+// Return a byte with the given value in the range [0, 1], which is clamped
+// otherwise
+func unitToByte(val float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, val)) * 0xff))
+}
+
+// Return the color given in hexadecimal notation, i.e., #rrggbb or #rgb, and
+// whether it was correctly written
+func parseHexColor(s string) (Color, bool) {
+
+	if len(s) < 1 || s[0] != '#' {
+		return Color{}, false
+	}
+
+	// In the short form every hexadecimal digit is duplicated
+	digits := s[1:]
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) != 6 {
+		return Color{}, false
+	}
+	val, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+
+	return Color{R: uint8(val >> 16), G: uint8(val >> 8), B: uint8(val)}, true
+}
+
+// Return the color given in a functional notation, i.e., rgb(), hsl(), hwb()
+// or oklch(), and whether it was correctly written. The name of the function
+// and its arguments are given separately. Arguments are separated either with
+// blanks or commas
+func parseFunctionalColor(function, args string) (Color, bool) {
+
+	comps := strings.Fields(strings.ReplaceAll(args, ",", " "))
+	if len(comps) != 3 {
+		return Color{}, false
+	}
+
+	switch function {
+	case "rgb":
+
+		// Channels are either in the range [0, 255] or percentages
+		var c [3]uint8
+		for idx, comp := range comps {
+			val, ok := parseComponent(comp, 0xff, false)
+			if !ok {
+				return Color{}, false
+			}
+			c[idx] = unitToByte(val / 0xff)
+		}
+		return Color{R: c[0], G: c[1], B: c[2]}, true
+
+	case "hsl", "hwb":
+
+		// The hue is followed by two percentages: saturation and lightness
+		// in HSL, or whiteness and blackness in HWB
+		h, ok1 := parseHue(comps[0])
+		x, ok2 := parseComponent(comps[1], 1, true)
+		y, ok3 := parseComponent(comps[2], 1, true)
+		if !ok1 || !ok2 || !ok3 {
+			return Color{}, false
+		}
+		x, y = math.Max(0, math.Min(1, x)), math.Max(0, math.Min(1, y))
+		if function == "hsl" {
+			r, g, b := utils.HslToRgbFloat(h/360, x, y)
+			return Color{R: unitToByte(r), G: unitToByte(g), B: unitToByte(b)}, true
+		}
+
+		// HWB colors are pure hues mixed with white and black. If the
+		// whiteness and blackness add up to 1 or more, the color is a gray
+		if x+y >= 1 {
+			gray := unitToByte(x / (x + y))
+			return Color{R: gray, G: gray, B: gray}, true
+		}
+		r, g, b := utils.HslToRgbFloat(h/360, 1, 0.5)
+		mix := func(c float64) uint8 {
+			return unitToByte(c*(1-x-y) + x)
+		}
+		return Color{R: mix(r), G: mix(g), B: mix(b)}, true
+
+	case "oklch":
+
+		// The lightness is in the range [0, 1] and the chroma is, in
+		// practice, in the range [0, 0.4], so that percentages are relative
+		// to them
+		l, ok1 := parseComponent(comps[0], 1, false)
+		ch, ok2 := parseComponent(comps[1], 0.4, false)
+		h, ok3 := parseHue(comps[2])
+		if !ok1 || !ok2 || !ok3 {
+			return Color{}, false
+		}
+		rad := h * math.Pi / 180
+		r, g, b := utils.OklabToRgb(l, ch*math.Cos(rad), ch*math.Sin(rad))
+		return Color{R: r, G: g, B: b}, true
+	}
+
+	return Color{}, false
+}
+
+// ParseColor returns the color written in the given string, which might be
+// given in any of the following notations, which are not case sensitive:
+//
+//   - Hexadecimal notation: #rgb or #rrggbb, e.g., #f80 or #ff8800
+//   - rgb(r g b), where every channel is in the range [0, 255] or a
+//     percentage, e.g., rgb(255 136 0)
+//   - hsl(h s l), where the hue is in degrees (or any other CSS unit: deg,
+//     grad, rad, turn), and the saturation and lightness are percentages,
+//     e.g., hsl(30 100% 50%)
+//   - hwb(h w b), where the hue is followed by the whiteness and blackness
+//     as percentages, e.g., hwb(30 0% 0%)
+//   - oklch(l c h), where the lightness is in the range [0, 1] (or a
+//     percentage), the chroma is usually below 0.4 (or a percentage of it)
+//     and the hue is in degrees, e.g., oklch(0.75 0.18 55)
+//   - CSS named colors, e.g., tomato. See [Named]
+//   - default, for the color used by default by the terminal. See [Default]
+//
+// Arguments of functional notations can be separated either with blanks or
+// commas. It returns an error wrapping ErrInvalidColor if the string is not
+// correctly written
+func ParseColor(s string) (Color, error) {
+
+	str := strings.ToLower(strings.TrimSpace(s))
+	if str == default_color_name {
+		return Default, nil
+	}
+	if c, ok := Named(str); ok {
+		return c, nil
+	}
+	if c, ok := parseHexColor(str); ok {
+		return c, nil
+	}
+	if open := strings.IndexByte(str, '('); open > 0 && strings.HasSuffix(str, ")") {
+		if c, ok := parseFunctionalColor(strings.TrimSpace(str[:open]), str[open+1:len(str)-1]); ok {
+			return c, nil
+		}
+	}
+
+	return Color{}, fmt.Errorf("golor: %w: %q", ErrInvalidColor, s)
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the color in hexadecimal notation, i.e., #rrggbb, or "default" for
+// [Default], which can be parsed back with [ParseColor]
+func (c Color) String() string {
+
+	if c.dflt {
+		return default_color_name
+	}
+
+	const digits = "0123456789abcdef"
+	return string([]byte{'#',
+		digits[c.R>>4], digits[c.R&0xf],
+		digits[c.G>>4], digits[c.G&0xf],
+		digits[c.B>>4], digits[c.B&0xf]})
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
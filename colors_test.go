@@ -0,0 +1,86 @@
+// -*- coding: utf-8 -*-
+// colors_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 13:41:52.661093478 (1792244512)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"errors"
+	"testing"
+)
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Colors written in any of the notations accepted by ParseColor are parsed as
+// in CSS
+func TestParseColor(t *testing.T) {
+
+	for _, test := range []struct {
+		s    string
+		want Color
+	}{
+
+		// hexadecimal notation
+		{"#f80", Color{R: 0xff, G: 0x88, B: 0x00}},
+		{"#FF8800", Color{R: 0xff, G: 0x88, B: 0x00}},
+		{"#102030", Color{R: 0x10, G: 0x20, B: 0x30}},
+
+		// rgb() with blanks, commas and percentages
+		{"rgb(255 136 0)", Color{R: 0xff, G: 0x88, B: 0x00}},
+		{"rgb(255, 136, 0)", Color{R: 0xff, G: 0x88, B: 0x00}},
+		{"rgb(100% 50% 0%)", Color{R: 0xff, G: 0x80, B: 0x00}},
+		{"rgb(100%, 50%, 0%)", Color{R: 0xff, G: 0x80, B: 0x00}},
+		{"RGB(1 2 3)", Color{R: 1, G: 2, B: 3}},
+
+		// hsl() and hwb() with units of angles
+		{"hsl(30 100% 50%)", Color{R: 0xff, G: 0x80, B: 0x00}},
+		{"hsl(30deg, 100%, 50%)", Color{R: 0xff, G: 0x80, B: 0x00}},
+		{"hsl(0.5turn 100% 50%)", Color{R: 0x00, G: 0xff, B: 0xff}},
+		{"hsl(30grad 100% 50%)", Color{R: 0xff, G: 0x73, B: 0x00}},
+		{"hsl(0 0% 50%)", Color{R: 0x80, G: 0x80, B: 0x80}},
+		{"hwb(30 10% 20%)", Color{R: 0xcc, G: 0x73, B: 0x1a}},
+		{"hwb(0.25turn 0% 0%)", Color{R: 0x80, G: 0xff, B: 0x00}},
+		{"hwb(0 60% 60%)", Color{R: 0x80, G: 0x80, B: 0x80}},
+
+		// oklch()
+		{"oklch(1 0 0)", Color{R: 0xff, G: 0xff, B: 0xff}},
+		{"oklch(0 0 0)", Color{R: 0x00, G: 0x00, B: 0x00}},
+		{"oklch(0.7 0.15 50)", Color{R: 0xe7, G: 0x7f, B: 0x3e}},
+		{"oklch(70% 0.1 200)", Color{R: 0x40, G: 0xb1, B: 0xb7}},
+
+		// named colors
+		{"tomato", Color{R: 0xff, G: 0x63, B: 0x47}},
+		{"SteelBlue", Color{R: 0x46, G: 0x82, B: 0xb4}},
+		{"  red  ", Color{R: 0xff, G: 0x00, B: 0x00}},
+		{"default", Default},
+	} {
+		got, err := ParseColor(test.s)
+		if err != nil || got != test.want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", test.s, got, err, test.want)
+		}
+	}
+}
+
+// Colors which are not correctly written are reported with ErrInvalidColor
+func TestParseColorErrors(t *testing.T) {
+
+	for _, s := range []string{
+		"", "#abcd", "#ff880", "#ggg", "#",
+		"rgb(1 2 3 4)", "rgb(1 2)", "rgba(1, 2, 3, 0.5)", "rgb()", "()",
+		"rgb(1 2 3", "hsl(red 100% 50%)", "oklch(0.7 0.1)", "nocolor",
+	} {
+		if got, err := ParseColor(s); !errors.Is(err, ErrInvalidColor) {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", s, got, err, ErrInvalidColor)
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,126 @@
+// -*- coding: utf-8 -*-
+// errors.go
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 22:51:44.182380168 (1792191104)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The following errors describe the different problems that might be found
+// when processing a format string, a markup string, a color or a color mode.
+// They are never returned directly but wrapped, usually in a [FormatError]
+// which also tells where they were found, so that they have to be tested with
+// errors.Is
+var (
+
+	// The argument given to a color verb is not a color specification
+	ErrUnsupportedEffect = errors.New("unsupported effect")
+
+	// There are less arguments than verbs in the format string
+	ErrMissingArgument = errors.New("missing argument")
+
+	// A verb in the format string is not correctly written, e.g., a color
+	// verb which is never closed
+	ErrMalformedVerb = errors.New("malformed verb")
+
+	// An inline color specification, e.g., %C(bold red){...}, contains words
+	// which are neither colors nor properties
+	ErrInvalidSpec = errors.New("invalid color specification")
+
+	// A color given as a string is not correctly written. See [ParseColor]
+	ErrInvalidColor = errors.New("invalid color")
+
+	// A tag in a markup string is not understood, e.g., <fg> without a color
+	ErrInvalidTag = errors.New("invalid tag")
+
+	// A closing tag in a markup string does not match the last tag opened, or
+	// a tag is never closed
+	ErrUnbalancedTag = errors.New("unbalanced tag")
+
+	// A color mode is neither auto, always nor never. See [ColorMode]
+	ErrInvalidColorMode = errors.New("invalid color mode")
+
+	// A color mode is set for a writer which can not be compared, e.g., a
+	// struct value with a slice. See [SetWriterColorMode]
+	ErrUncomparableWriter = errors.New("uncomparable writer")
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A FormatError describes a problem found when processing a format string.
+// Err is (or wraps) one of the errors defined above, and Offset is the position
+// (in bytes) in the format string of the verb where it was found, or in the
+// markup string of the offending tag
+type FormatError struct {
+	Offset int
+	Err    error
+	Arg    any
+}
+
+// The following types are the errors returned by golor.Errorf when the verb %w
+// is used with one or more errors, respectively. As those returned by
+// fmt.Errorf, they provide an Unwrap method to access them
+type wrapError struct {
+	msg string
+	err error
+}
+
+type wrapErrors struct {
+	msg  string
+	errs []error
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return a description of the error including its location in the format
+// string and, if any, the offending argument
+func (e *FormatError) Error() string {
+
+	if e.Arg != nil {
+		return fmt.Sprintf("golor: %v (%T=%v) at offset %v", e.Err, e.Arg, e.Arg, e.Offset)
+	}
+	return fmt.Sprintf("golor: %v at offset %v", e.Err, e.Offset)
+}
+
+// Return the error wrapped in the FormatError so that it can be inspected with
+// errors.Is
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// Return the message of the error
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+// Return the error wrapped with the verb %w
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// Return the message of the error
+func (e *wrapErrors) Error() string {
+	return e.msg
+}
+
+// Return all the errors wrapped with the verb %w
+func (e *wrapErrors) Unwrap() []error {
+	return e.errs
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,233 @@
+// -*- coding: utf-8 -*-
+// format.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 00:26:13.905518264 (1792196773)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A Format is a format string which has been already parsed, so that it can be
+// used many times without parsing it again. It is created with [Compile] or
+// [MustCompile], and it provides the same services than the functions of the
+// Printf family. It is safe for concurrent use by multiple goroutines
+type Format struct {
+	format string
+	nodes  []node
+	nargs  int
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Update the argument to use next according to the given explicit argument
+// index, if any. It returns an error if the index is not correctly written
+func checkArgIndex(index argIndex, argNum, offset int) (int, error) {
+
+	if !index.present {
+		return argNum, nil
+	}
+	if !index.ok || index.n < 1 {
+		return argNum, &FormatError{Offset: offset, Err: ErrMalformedVerb}
+	}
+
+	return index.n - 1, nil
+}
+
+// Check that the flags, width, precision and explicit argument indexes of the
+// given verb are correctly written, and compute the arguments consumed by the
+// width and precision given with '*'. argNum is the argument to use next, and
+// nargs the number of arguments required so far. It returns their updated
+// values, so that argNum is the argument of the verb itself
+func checkSpec(spec *verbSpec, argNum, nargs, offset int) (int, int, error) {
+
+	var err error
+	if spec.badArgNumber || spec.verb == no_verb {
+		return argNum, nargs, &FormatError{Offset: offset, Err: ErrMalformedVerb}
+	}
+	if argNum, err = checkArgIndex(spec.widthIndex, argNum, offset); err != nil {
+		return argNum, nargs, err
+	}
+	if spec.widthStar {
+		argNum++
+		nargs = max(nargs, argNum)
+	}
+	if argNum, err = checkArgIndex(spec.precIndex, argNum, offset); err != nil {
+		return argNum, nargs, err
+	}
+	if spec.precStar {
+		argNum++
+		nargs = max(nargs, argNum)
+	}
+	if argNum, err = checkArgIndex(spec.verbIndex, argNum, offset); err != nil {
+		return argNum, nargs, err
+	}
+
+	return argNum, nargs, nil
+}
+
+// Check that all the verbs in the given nodes are correctly written, and
+// compute the number of arguments they require. argNum is the argument to use
+// next, and nargs the number of arguments required so far. It returns their
+// updated values and the first error found, if any
+func checkNodes(nodes []node, argNum, nargs int) (int, int, error) {
+
+	var err error
+	for idx := range nodes {
+
+		n := &nodes[idx]
+		switch n.kind {
+		case malformedNode:
+
+			// Color verbs which are never closed consume their arguments
+			// anyway, as they are substituted
+			if n.effectArg() {
+				if argNum, nargs, err = checkSpec(&n.spec, argNum, nargs, n.offset); err == nil {
+					nargs = max(nargs, argNum+1)
+				}
+			}
+			return argNum, nargs, &FormatError{Offset: n.offset, Err: ErrMalformedVerb}
+
+		case verbNode:
+
+			// Check the width, the precision and the verb. The percent sign
+			// does not consume any argument
+			if argNum, nargs, err = checkSpec(&n.spec, argNum, nargs, n.offset); err != nil {
+				return argNum, nargs, err
+			}
+			if n.spec.verb != '%' {
+				argNum++
+				nargs = max(nargs, argNum)
+			}
+
+		case colorNode:
+
+			// Check the width, the precision and the effect of the color
+			// verb, and then its contents
+			if argNum, nargs, err = checkSpec(&n.spec, argNum, nargs, n.offset); err != nil {
+				return argNum, nargs, err
+			}
+			argNum++
+			nargs = max(nargs, argNum)
+			if argNum, nargs, err = checkNodes(n.children, argNum, nargs); err != nil {
+				return argNum, nargs, err
+			}
+
+		case inlineNode:
+
+			// Inline specifications do not consume any argument
+			if n.err != nil {
+				return argNum, nargs, &FormatError{Offset: n.offset, Err: n.err}
+			}
+			if argNum, nargs, err = checkNodes(n.children, argNum, nargs); err != nil {
+				return argNum, nargs, err
+			}
+		}
+	}
+
+	return argNum, nargs, nil
+}
+
+// Compile parses a format string and returns a [Format] that can be used many
+// times to substitute its verbs with different arguments. It returns an error
+// of type *FormatError if any verb is not correctly written, e.g., a color
+// verb which is never closed or whose inline specification is not valid
+func Compile(format string) (*Format, error) {
+
+	nodes := parse(format)
+	_, nargs, err := checkNodes(nodes, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Format{format: format, nodes: nodes, nargs: nargs}, nil
+}
+
+// MustCompile is like [Compile] but panics if the format string can not be
+// parsed. It simplifies the initialization of global variables holding
+// compiled formats
+func MustCompile(format string) *Format {
+
+	f, err := Compile(format)
+	if err != nil {
+		panic(`golor: Compile(` + strconv.Quote(format) + `): ` + err.Error())
+	}
+
+	return f
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the format string used to compile this format
+func (f *Format) String() string {
+	return f.format
+}
+
+// Return the number of arguments required by this format, including the
+// arguments of color verbs and those given with '*'. If explicit argument
+// indexes are used, it is the largest index. It can be used to validate the
+// arguments before substituting the verbs
+func (f *Format) NumArgs() int {
+	return f.nargs
+}
+
+// Substitute the verbs of this format with the given arguments as golor.Printf
+// does, and write the result to the standard output. It returns the number of
+// bytes written and any error encountered
+func (f *Format) Printf(a ...any) (n int, err error) {
+	return f.Fprintf(os.Stdout, a...)
+}
+
+// Substitute the verbs of this format with the given arguments as
+// golor.Sprintf does, and return the resulting string
+func (f *Format) Sprintf(a ...any) string {
+
+	p := newPrinter()
+	p.doPrintf(f.nodes, a)
+	s := string(p.buf)
+	p.free()
+	return s
+}
+
+// Substitute the verbs of this format with the given arguments as
+// golor.Fprintf does, and write the result to the given writer. It returns the
+// number of bytes written and any error encountered
+func (f *Format) Fprintf(w io.Writer, a ...any) (n int, err error) {
+
+	p := newPrinter()
+	p.setWriter(w)
+	p.doPrintf(f.nodes, a)
+	if n, err = w.Write(p.buf); err == nil {
+		err = p.err
+	}
+	p.free()
+	return
+}
+
+// Substitute the verbs of this format with the given arguments as
+// golor.Appendf does, and append the result to the byte slice. It returns the
+// updated slice
+func (f *Format) Append(b []byte, a ...any) []byte {
+
+	p := newPrinter()
+	p.doPrintf(f.nodes, a)
+	b = append(b, p.buf...)
+	p.free()
+	return b
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -17,8 +17,73 @@
 //   - The argument given to %C{...} has to be a color specification.
 //
 //   - The ellipsis stands for any text which might also contain other verbs to be
-//     substituted. Currently, color verbs can not be nested and thus the text
-//     enclosed in a color verb can not contain %C{...}
+//     substituted, including other color verbs. When a nested color verb ends,
+//     the effects of all the color verbs enclosing it are restored
+//
+// Verbs other than %C{...} follow exactly the same grammar than in fmt,
+// including any combination of flags, widths and precisions given with '*',
+// explicit argument indexes such as %[2]d and the literal percent sign %%, and
+// they consume the same arguments and produce the same output than in fmt.
+//
+// Because a closing brace ends the text of a color verb, it has to be escaped
+// with a backslash to be shown inside a color verb, i.e., \}. Likewise, \\
+// stands for a single backslash. Escape sequences are recognized only inside
+// color verbs, and any other backslash is shown as is:
+//
+//	golor.Printf(`%C{{"name": %q\}}`+"\n", green, "golor")
+//
+// Explicit argument indexes can also be given to color verbs, e.g., %[1]C{...},
+// so that the same effect can be used several times in a single format string
+// without repeating the argument:
+//
+//	golor.Printf("%[1]C{%[2]s} and %[1]C{%[3]s}\n", red, "this", "that")
+//
+// Effects can also be described with strings using the vocabulary of git
+// config, e.g., "bold ul #ff8800 on navy", which can be given as the argument
+// of color verbs, parsed with [ParseEffect], or written inline in the format
+// string between parentheses right before the braces, e.g., %C(bold red on
+// #102030){...}. In the latter case, the color verb does not consume any
+// argument. Specifications are lists of words separated by blanks: names of
+// properties (bold, dim, italic, ul, blink and strike, among others), and
+// colors written in any notation accepted by [ParseColor], e.g., navy, #102030
+// or rgb(255 136 0). The first color is the foreground color, and the second
+// one, which can be preceded by "on", is the background color:
+//
+//	golor.Printf("%C(bold yellow){warning:} %s\n", msg)
+//
+// Color verbs also accept a width, a precision and the flag '-', e.g.,
+// %-24C{%s:%d}, which apply to their whole contents and are measured in
+// visible runes, i.e., ignoring escape sequences. The contents are truncated to
+// the precision and padded with blanks to the width, to the left or, with '-',
+// to the right, so that colored columns are correctly aligned. As in fmt, the
+// width and precision can be given as arguments with '*', which are consumed
+// before the effect.
+//
+// golor provides a counterpart of every function of the fmt Printf family:
+// Printf, Sprintf, Fprintf, Appendf and Errorf accept color verbs in their
+// format string, whereas Print, Println, Sprint, Sprintln, Fprint, Fprintln,
+// Append and Appendln format their operands exactly as their fmt counterparts
+// do. Hence, golor can replace fmt everywhere in a codebase. Errorf supports
+// wrapping errors with %w, also inside color verbs.
+//
+// Format strings can also be parsed only once with [Compile] or [MustCompile].
+// The resulting [Format] provides the same services without parsing the format
+// string again:
+//
+//	logLine := golor.MustCompile("%C{%-5s} %s:%d\n")
+//	logLine.Fprintf(os.Stderr, red, "ERROR", file, line)
+//
+// As an alternative to color verbs, text can be colored with a small markup
+// language using [Printm], [Sprintm] or [Fprintm]. Tags can be nested, and they
+// are either properties (<bold> or <b>, <italic> or <i>, <underline> or <u>,
+// <strike> or <s>, <dim>, <blink> and <reverse>, among others), or colors
+// given with <fg color> and <bg color>, where colors are written as in inline
+// specifications. A literal less-than sign is written as \<:
+//
+//	golor.Printm("<b><fg #f80>warning:</fg></b> disk almost full\n")
+//
+// Problems with the tags are shown with markers, and they are located with
+// [CheckMarkup].
 //
 // The following discussions are exemplified only with golor.Printf but they
 // work in the same way with either golor.Sprintf or golor.Fprintf. The output
@@ -48,7 +113,44 @@
 //	    Properties: golor.ITALIC}
 //	golor.Printf("Happy %C{%v}!\n", effect, "coloring")
 //
-// which shows the word "coloring" in pink with a black background
+// which shows the word "coloring" in pink with a black background. Use
+// [Default] for any of the colors to show instead the color used by default by
+// the terminal, which is shown with the codes 39 (foreground) and 49
+// (background), or [DEFAULT_FG64] with values of type [Effect64]. Underlines
+// can also be given a style, e.g., [UnderlineCurly], and a color, which are
+// shown as plain underlines by terminals which do not support them
+//
+// Colors can also be taken from the ANSI palettes with 16 and 256 colors using
+// the types [Ansi16] and [Ansi256], which are supported by almost every
+// terminal. They can be given alone to set the foreground color, or combined
+// with the types [EffectAnsi16] and [EffectAnsi256]:
+//
+//	golor.Printf("%C{%s}\n", golor.Ansi256(208), "warning")
+//
+// Alternatively, effects can be given with 24-bit colors and shown with the
+// colors supported by the terminal, which are described with a
+// [ColorProfile] set with [SetColorProfile]. Colors are then substituted by
+// the perceptually closest colors of the ANSI palettes, and no escape
+// sequences are issued at all with the profile [Ascii]. By default, the
+// profile is detected separately for the standard output and error (see
+// [DetectColorProfile]), so that no escape sequences are written when they
+// are not terminals. Whether colors are shown at all can be decided with a
+// [ColorMode], either for all writers with [SetColorMode] or for specific ones
+// with [SetWriterColorMode], and the environment variables NO_COLOR,
+// FORCE_COLOR and CLICOLOR are obeyed
+//
+// Effects can also be built with chainable methods using a [Style], which can
+// be given to color verbs as well, or used for rendering text directly:
+//
+//	warning := golor.NewStyle().Fg(golor.Color{R: 0xff, G: 0x88}).Bold()
+//	golor.Printf("%C{%s}\n", warning, "careful")
+//	fmt.Println(warning.Render("careful"))
+//
+// Finally, single values can be colored with [Styled] even when they are given
+// to the functions of the fmt package, which is useful for coloring the
+// arguments of third-party code. Widths are then measured in visible runes:
+//
+//	fmt.Printf("%-12v|\n", golor.Styled(name, warning))
 //
 // Note that %C{...} also allows using any other verbs, e.g.:
 //
@@ -57,15 +159,23 @@
 // which shows the contents of the definition of the variable effect defined
 // above in pink
 //
+// Color verbs can be nested. In the following example, the whole sentence is
+// shown in red, but for the name of the file which is shown in bold yellow.
+// Once the inner color verb ends, the rest of the sentence is shown again in
+// red
+//
+//	golor.Printf("%C{Error in %C{%s} at line %d}\n", red, boldYellow, file, line)
+//
 // See the [README.md] file for more information
 //
 // [README.md]: https://github.com/clinaresl/golor/blob/main/README.md
 package golor
 
 import (
-	"fmt"
 	"io"
-	"regexp"
+	"os"
+	"reflect"
+	"strings"
 )
 
 // Constants
@@ -77,6 +187,8 @@ const (
 	prefix             = "\033["
 	foreground_prefix  = "38;2"
 	background_prefix  = "48;2"
+	default_foreground = "39"
+	default_background = "49"
 	bold_prefix        = "1"
 	dim_prefix         = "2"
 	italic_prefix      = "3"
@@ -84,6 +196,14 @@ const (
 	slow_blink_prefix  = "5"
 	rapid_blink_prefix = "6"
 	crossed_out_prefix = "9"
+	reverse_prefix     = "7"
+	conceal_prefix     = "8"
+	double_ul_prefix   = "21"
+	overline_prefix    = "53"
+	framed_prefix      = "51"
+	encircled_prefix   = "52"
+	superscript_prefix = "73"
+	subscript_prefix   = "74"
 	suffix             = "\033[0m"
 )
 
@@ -98,7 +218,7 @@ const (
 	fg_blue32    = 0x0000ff
 
 	// Specification with uin64
-	properties64 = 0x00ff000000000000
+	properties64 = 0x7fff000000000000
 	bg_red64     = 0xff0000000000
 	bg_green64   = 0x00ff00000000
 	bg_blue64    = 0x0000ff000000
@@ -114,10 +234,19 @@ const (
 	SLOW_BLINK
 	RAPID_BLINK
 	CROSSED_OUT
+	REVERSE
+	CONCEAL
+	DOUBLE_UNDERLINE
+	OVERLINE
+	FRAMED
+	ENCIRCLED
+	SUPERSCRIPT
+	SUBSCRIPT
 )
 
 // The following constants must be used for defining properties with the type
-// [Effect32]
+// [Effect32]. Since properties are given in the highest byte, the rest of
+// properties can not be used with this type
 const (
 	BOLD32 = 1 << (iota + 24)
 	DIM32
@@ -126,10 +255,11 @@ const (
 	SLOW_BLINK32
 	RAPID_BLINK32
 	CROSSED_OUT32
+	REVERSE32
 )
 
 // The following constants must be used for defining properties with the type
-// [Effect64]
+// [Effect64], which are given in the bits 48 to 62
 const (
 	BOLD64 = 1 << (iota + 48)
 	DIM64
@@ -138,55 +268,80 @@ const (
 	SLOW_BLINK64
 	RAPID_BLINK64
 	CROSSED_OUT64
+	REVERSE64
+	CONCEAL64
+	DOUBLE_UNDERLINE64
+	OVERLINE64
+	FRAMED64
+	ENCIRCLED64
+	SUPERSCRIPT64
+	SUBSCRIPT64
 )
 
+// The following constant can be used with the type [Effect64] for showing the
+// foreground color used by default by the terminal instead of the one given in
+// the lowest 24 bits, e.g., for setting only the background color
+const DEFAULT_FG64 = 1 << 63
+
 // Provide a map between properties and their sequence
-var propertyPrefix = map[uint8]string{
-	BOLD:        bold_prefix,
-	DIM:         dim_prefix,
-	ITALIC:      italic_prefix,
-	UNDERLINE:   underline_prefix,
-	SLOW_BLINK:  slow_blink_prefix,
-	RAPID_BLINK: rapid_blink_prefix,
-	CROSSED_OUT: crossed_out_prefix,
+var propertyPrefix = map[uint16]string{
+	BOLD:             bold_prefix,
+	DIM:              dim_prefix,
+	ITALIC:           italic_prefix,
+	UNDERLINE:        underline_prefix,
+	SLOW_BLINK:       slow_blink_prefix,
+	RAPID_BLINK:      rapid_blink_prefix,
+	CROSSED_OUT:      crossed_out_prefix,
+	REVERSE:          reverse_prefix,
+	CONCEAL:          conceal_prefix,
+	DOUBLE_UNDERLINE: double_ul_prefix,
+	OVERLINE:         overline_prefix,
+	FRAMED:           framed_prefix,
+	ENCIRCLED:        encircled_prefix,
+	SUPERSCRIPT:      superscript_prefix,
+	SUBSCRIPT:        subscript_prefix,
 }
 
-// The following regular expression is used for matching any verb, thouse used
-// in the fmt Printf family function, and also the verb %C{...}
-const all_verbs_regexp = `%(C\{([^\}]+)\}|(?<flags>[-+#0 ])?(?<width>\d+|\*)?(?:\.(?<precision>\d+|\*))?(?<length>[hljztL]|hh|ll)?(?<specifier>[diuoxXfFeEgGaAcspnTv]))`
-
-// The following regular expression is used instead for matching color codes
-// only %C{...}
-const color_regexp = `^%C\{([^\}]+)\}`
-
 // Types
 // ----------------------------------------------------------------------------
 
-// The following type defines an RGB color to be used only with type [Effect]
+// The following type defines an RGB color to be used only with type [Effect].
+// Colors must be written as keyed composite literals, e.g., Color{R: 0xff},
+// because of the unexported field used for telling [Default] apart
 type Color struct {
 	R, G, B uint8
+
+	// dflt is true only for Default, i.e., the color used by default by the
+	// terminal, which has no RGB components
+	dflt bool
 }
 
 // The following type defines a combination of foreground, background colors and
 // properties. Note that both the foreground and background colors have to be of
-// type [Color]
+// type [Color], so that the zero value of any of them is black. Use [Default]
+// for showing the colors used by default by the terminal instead. Underlines
+// can be given a style, e.g., [UnderlineCurly], and a color, which are shown
+// only by terminals which support them, and as plain underlines otherwise. If
+// no color is given for underlines, they are shown with the color of the text
 type Effect struct {
-	Fg, Bg     Color
-	Properties uint8
+	Fg, Bg         Color
+	Properties     uint16
+	Underline      UnderlineStyle
+	UnderlineColor *Color
 }
 
 // The following type defines a combination of foreground color and properties.
 // The foregrround color must be given with three different bytes
 type FgEffect struct {
 	R, G, B    uint8
-	Properties uint8
+	Properties uint16
 }
 
 // The following type defines a combination of background color and properties.
 // The backgrround color must be given with three different bytes
 type BgEffect struct {
 	R, G, B    uint8
-	Properties uint8
+	Properties uint16
 }
 
 // It is also possible to define just the foreground color and the properties
@@ -200,306 +355,261 @@ type Effect64 = uint64
 // Variables
 // ----------------------------------------------------------------------------
 
-// (Must)Compiled regexps
-var allVerbs = regexp.MustCompile(all_verbs_regexp)
-var colorVerb = regexp.MustCompile(color_regexp)
+// Default is the color used by default by the terminal, which is shown with the
+// codes 39 (foreground) and 49 (background). Unlike black, it looks right both
+// in dark and light terminals, e.g., Effect{Fg: Red, Bg: Default} shows red
+// text without painting its background
+var Default = Color{dflt: true}
 
 // Functions
 // ----------------------------------------------------------------------------
 
-// Process the specified color specification and return a string representing
-// the foreground color. It returns an error in case the specification is given
-// in an unknown format
-func processForegroundColor(arg any) (output string, err error) {
+// Return the rendition of the given color specification, i.e., its foreground
+// and background colors (if any) and its properties. It returns
+// ErrUnsupportedEffect in case the specification is given in an unknown
+// format, and an error wrapping ErrInvalidSpec if it is a string which is not
+// correctly written
+func effectRendition(arg any) (r rendition, err error) {
 
-	// This package supports various formats for specifying colors
+	// This package supports various formats for specifying colors and
+	// properties
 	switch val := arg.(type) {
 
 	case Effect:
 
-		output = fmt.Sprintf("%v;%v;%v", val.Fg.R, val.Fg.G, val.Fg.B)
+		r = rendition{fg: rgbTermColor(val.Fg), bg: rgbTermColor(val.Bg), properties: val.Properties, ulStyle: val.Underline}
+		if val.UnderlineColor != nil {
+			r.ulColor = rgbTermColor(*val.UnderlineColor)
+		}
 
 	case FgEffect:
 
-		output = fmt.Sprintf("%v;%v;%v", val.R, val.G, val.B)
+		// This type does not provide information about the background color
+		r = rendition{fg: rgbTermColor(Color{R: val.R, G: val.G, B: val.B}), properties: val.Properties}
 
 	case BgEffect:
 
 		// This type does not provide information about the foreground color
-		break
+		r = rendition{bg: rgbTermColor(Color{R: val.R, G: val.G, B: val.B}), properties: val.Properties}
 
 	case Effect32:
 
-		output = fmt.Sprintf("%v;%v;%v", (val&fg_red32)>>16, (val&fg_green32)>>8, val&fg_blue32)
+		// This type does not provide information about the background color
+		r = rendition{
+			fg:         rgbTermColor(Color{R: uint8((val & fg_red32) >> 16), G: uint8((val & fg_green32) >> 8), B: uint8(val & fg_blue32)}),
+			properties: uint16((val & properties32) >> 24)}
 
 	case Effect64:
 
-		output = fmt.Sprintf("%v;%v;%v", (val&fg_red32)>>16, (val&fg_green32)>>8, val&fg_blue32)
+		r = rendition{
+			fg:         rgbTermColor(Color{R: uint8((val & fg_red32) >> 16), G: uint8((val & fg_green32) >> 8), B: uint8(val & fg_blue32)}),
+			bg:         rgbTermColor(Color{R: uint8((val & bg_red64) >> 40), G: uint8((val & bg_green64) >> 32), B: uint8((val & bg_blue64) >> 24)}),
+			properties: uint16((val & properties64) >> 48)}
 
-	default:
-		return "", fmt.Errorf("Unsuported foreground color format: %v\n", arg)
-	}
-
-	return
-}
+		// The foreground color might be the one used by default
+		if val&DEFAULT_FG64 != 0 {
+			r.fg = rgbTermColor(Default)
+		}
 
-// Process the specified color specification and return a string representing
-// the background color. It returns an error in case the specification is given
-// in an unknown format
-func processBackgroundColor(arg any) (output string, err error) {
+	case Ansi16:
 
-	// This package supports various formats for specifying colors
-	switch val := arg.(type) {
+		// Colors of the ANSI palettes given alone set the foreground color
+		r = rendition{fg: ansi16TermColor(val)}
 
-	case Effect:
+	case Ansi256:
 
-		output = fmt.Sprintf("%v;%v;%v", val.Bg.R, val.Bg.G, val.Bg.B)
+		r = rendition{fg: ansi256TermColor(val)}
 
-	case FgEffect:
+	case EffectAnsi16:
 
-		// This type does not provide information about the background color
-		break
+		r = rendition{fg: ansi16TermColor(val.Fg), properties: val.Properties}
+		if val.Bg != nil {
+			r.bg = ansi16TermColor(*val.Bg)
+		}
 
-	case BgEffect:
+	case EffectAnsi256:
 
-		output = fmt.Sprintf("%v;%v;%v", val.R, val.G, val.B)
+		r = rendition{fg: ansi256TermColor(val.Fg), properties: val.Properties}
+		if val.Bg != nil {
+			r.bg = ansi256TermColor(*val.Bg)
+		}
 
-	case Effect32:
+	case Style:
 
-		// This type does not provide information about the background color
-		break
+		r = val.r
 
-	case Effect64:
+	case string:
 
-		output = fmt.Sprintf("%v;%v;%v", (val&bg_red64)>>40, (val&bg_green64)>>32, val&bg_blue64>>24)
+		// Strings are textual color specifications
+		return parseSpec(val)
 
 	default:
-		return "", fmt.Errorf("Unsuported background color format: %v\n", arg)
+		return rendition{}, ErrUnsupportedEffect
 	}
 
 	return
 }
 
-// Process the specified properties and return the string with its ANSI codes
-func processProperties(properties uint8) (output string) {
+// Return a format string with a %v verb for every operand given, separated as
+// fmt.Sprint does, i.e., with a blank between operands when neither is a
+// string. If ln is true, operands are always separated by a blank and the
+// format ends with a newline as fmt.Sprintln does
+func operandsFormat(ln bool, a ...any) string {
 
-	// Process all properties one by one
-	var idx uint8
-	for idx = BOLD; idx <= CROSSED_OUT; idx <<= 1 {
+	var format strings.Builder
+	for idx, arg := range a {
 
-		if properties&idx != 0 {
-			output += fmt.Sprintf(";%v", propertyPrefix[idx])
+		// Operands are preceded by a blank if this is not the first one and
+		// either ln is true, or neither this operand nor the previous one is a
+		// string
+		if idx > 0 && (ln || (!isString(arg) && !isString(a[idx-1]))) {
+			format.WriteByte(' ')
 		}
+		format.WriteString("%v")
+	}
+	if ln {
+		format.WriteByte('\n')
 	}
 
-	return
+	return format.String()
 }
 
-// Given a string chunk, return it preceded by the color prefix corresponding to
-// the given argument and ended with the corresponding suffix
-func substituteColorVerb(chunk string, arg any) (output string, err error) {
-
-	// This package supports various formats for specifying colors and
-	// properties
-	switch val := arg.(type) {
-
-	case Effect:
-
-		// Get the foreground and background specs
-		fg, fgerr := processForegroundColor(val)
-		bg, bgerr := processBackgroundColor(val)
-		if fgerr != nil {
-			return "", err
-		}
-		if bgerr != nil {
-			return "", err
-		}
-
-		output = fmt.Sprintf(`%v%v;%v;%v;%v%vm%v%v`, prefix, foreground_prefix, fg, background_prefix, bg, processProperties(val.Properties), chunk, suffix)
-
-	case FgEffect:
-
-		// Get the foreground spec
-		fg, fgerr := processForegroundColor(val)
-		if fgerr != nil {
-			return "", err
-		}
-
-		output = fmt.Sprintf(`%v%v;%v%vm%v%v`, prefix, foreground_prefix, fg, processProperties(val.Properties), chunk, suffix)
-
-	case BgEffect:
-
-		// Get the background specs
-		bg, bgerr := processBackgroundColor(val)
-		if bgerr != nil {
-			return "", err
-		}
-
-		output = fmt.Sprintf(`%v%v;%v%vm%v%v`, prefix, background_prefix, bg, processProperties(val.Properties), chunk, suffix)
-
-	case Effect32:
-
-		// Get the foreground spec
-		fg, fgerr := processForegroundColor(val)
-		if fgerr != nil {
-			return "", err
-		}
-
-		output = fmt.Sprintf(`%v%v;%v%vm%v%v`, prefix, foreground_prefix, fg, processProperties(uint8((val&properties32)>>24)), chunk, suffix)
+// Return true if the given argument is a string, as considered by fmt.Sprint
+// for separating operands
+func isString(arg any) bool {
+	return arg != nil && reflect.TypeOf(arg).Kind() == reflect.String
+}
 
-	case Effect64:
+// golor.Printf is the counterpart of fmt.Printf. It substitutes the color
+// verbs (%C{...}) and all the other verbs exactly as fmt.Printf does, and
+// writes the result to the standard output. It returns the number of bytes
+// written and any write error encountered. If there are problems with the
+// format string or the arguments, the output is written anyway with markers
+// describing them, e.g., %!C(MISSING), and the first one is returned as an
+// error of type *FormatError unless a write error happens. Colors are shown
+// with the color profile detected for the standard output, so that no escape
+// sequences are written if it is not a terminal, e.g., a pipe
+func Printf(format string, a ...any) (n int, err error) {
+	return Fprintf(os.Stdout, format, a...)
+}
 
-		// Get the foreground and background specs
-		fg, fgerr := processForegroundColor(val)
-		bg, bgerr := processBackgroundColor(val)
-		if fgerr != nil {
-			return "", err
-		}
-		if bgerr != nil {
-			return "", err
-		}
+// golor.Sprintf is the counterpart of fmt.Sprintf. It substitutes the color
+// verbs (%C{...}) and all the other verbs exactly as fmt.Sprintf does. It
+// returns the resulting string. If there are problems with the format string or
+// the arguments, the resulting string contains markers describing them, e.g.,
+// %!C(MISSING)
+func Sprintf(format string, a ...any) string {
 
-		output = fmt.Sprintf(`%v%v;%v;%v;%v%vm%v%v`, prefix, foreground_prefix, fg, background_prefix, bg, processProperties(uint8(val&properties64>>48)), chunk, suffix)
+	p := newPrinter()
+	p.doPrintf(parseCached(format), a)
+	s := string(p.buf)
+	p.free()
+	return s
+}
 
-	default:
-		return "", fmt.Errorf("Unsupported format: %v\n", arg)
+// golor.Fprintf is the counterpart of fmt.Fprintf. It substitutes the color
+// verbs (%C{...}) and all the other verbs exactly as fmt.Fprintf does, and
+// writes the result to the given writer. It returns the number of bytes written
+// and any write error encountered. Problems with the format string or the
+// arguments are reported as in golor.Printf. Colors are shown with the color
+// profile detected for files (see DetectColorProfile), and with the profile
+// TrueColor for any other writer, unless a profile is set with SetColorProfile
+func Fprintf(w io.Writer, format string, a ...any) (n int, err error) {
+
+	p := newPrinter()
+	p.setWriter(w)
+	p.doPrintf(parseCached(format), a)
+	if n, err = w.Write(p.buf); err == nil {
+		err = p.err
 	}
-
+	p.free()
 	return
 }
 
-// substitute all occurrences of color verbs by their corresponding prefixes and
-// suffixes without affecting the other verbs in the format string. It returns:
-//
-//  1. The resulting string with all color verbs properly substituted,
-//
-//  2. The list of arguments to be used in the substitution of the remaining verbs,
-//
-//  3. The number of arguments consumed in the substitution of non-color verbs.
-//
-//  4. An error in case any is found.
-func processColorVerbs(format string, a ...any) (output string, args []any, nargs int, err error) {
-
-	// Keep a counter over the arguments given in a to know which ones to use
-	// for substituting the color verbs, and which to use in the substitutions
-	// performed by the Printf family
-	var idx int
-
-	// An offset is needed to know the position from which the last chunk of
-	// format was copied
-	var offset int
-
-	// Get the location of all verbs and process them all
-	matches := allVerbs.FindAllStringSubmatchIndex(format, -1)
-	for _, match := range matches {
-
-		// Check whether this is a color verb
-		if colorVerb.MatchString(format[match[0]:match[1]]) {
-
-			// First, process the contents of the color verb. Notice that all
-			// the remaining args are used, but the first one which must be used
-			// later for substituting the color verb
-			contents, cargs, cnargs, cerr := processColorVerbs(format[match[0]+3:match[1]-1], a[idx+1:]...)
-			if cerr != nil {
-				return "", nil, 0, err
-			}
-
-			// copy from the previous offset until the end of the color verb,
-			// substitute it, and update the offset
-			if chunk, err := substituteColorVerb(contents, a[idx]); err == nil {
-				output += format[offset:match[0]]
-				output += chunk
-				offset = match[1]
-			} else {
-				return "", nil, 0, err
-			}
-
-			// Copy all the necessary args to make the necessary substitutions
-			// later inside the color-verb
-			for i := 0; i < cnargs; i++ {
-
-				// In fact, these are given by the result of the recursive
-				// invocation of ProcessColorVerbs, but not all of them have to
-				// be copied, only those consumed in the last processing
-				args = append(args, cargs[i])
-			}
-
-			// and update the counter of the next arguments to used. Again, note
-			// we add 1 to avoid re-using the argument of the color verb
-			idx += 1 + cnargs
-		} else {
-
-			// Otherwise, copy all elements in the output and update the offset
-			output += format[offset:match[1]]
-			offset = match[1]
-
-			// and preserve this argument to be used a posteriori
-			nargs++
-			args = append(args, a[idx])
-
-			// and update the counter of the next argument to use.
-			idx++
-		}
-	}
-
-	// Finally, add to the output string the rest of it since the last offset,
-	// and also any other argument that have not been used (this is relevant
-	// when procesing nested chunks of the original string)
-	output += format[offset:]
-	if len(a) > idx {
-		args = append(args, a[idx:]...)
-	}
-
-	return
+// golor.Print is the counterpart of fmt.Print. It formats its operands using
+// the default formats as fmt.Print does and writes them to the standard output.
+// It returns the number of bytes written and any write error encountered.
+func Print(a ...any) (n int, err error) {
+	return Printf(operandsFormat(false, a...), a...)
 }
 
-// golor.Printf is the counterpart of fmt.Printf. It just substitutes the color
-// verbs (%C{...}) and queries fmt.Printf to substitute the rest. It returns the
-// number of bytes written and any write error encountered.
-func Printf(format string, a ...any) (n int, err error) {
+// golor.Println is the counterpart of fmt.Println. It formats its operands
+// using the default formats as fmt.Println does and writes them to the standard
+// output. It returns the number of bytes written and any write error
+// encountered.
+func Println(a ...any) (n int, err error) {
+	return Printf(operandsFormat(true, a...), a...)
+}
 
-	// First, substitute all the color verbs
-	cformat, cargs, _, cerr := processColorVerbs(format, a...)
-	if cerr != nil {
-		return 0, err
-	}
+// golor.Sprint is the counterpart of fmt.Sprint. It formats its operands using
+// the default formats as fmt.Sprint does and returns the resulting string
+func Sprint(a ...any) string {
+	return Sprintf(operandsFormat(false, a...), a...)
+}
 
-	// Let fmt.Printf do the rest of the job
-	return fmt.Printf(cformat, cargs...)
+// golor.Sprintln is the counterpart of fmt.Sprintln. It formats its operands
+// using the default formats as fmt.Sprintln does and returns the resulting
+// string
+func Sprintln(a ...any) string {
+	return Sprintf(operandsFormat(true, a...), a...)
 }
 
-// golor.Printf is the counterpart of fmt.Sprintf. It just substitutes the color
-// verbs (%C{...}) and queries fmt.Sprintf to substitute the rest. It returns the
-// resulting string
-func Sprintf(format string, a ...any) string {
+// golor.Fprint is the counterpart of fmt.Fprint. It formats its operands using
+// the default formats as fmt.Fprint does and writes them to the given writer.
+// It returns the number of bytes written and any write error encountered.
+func Fprint(w io.Writer, a ...any) (n int, err error) {
+	return Fprintf(w, operandsFormat(false, a...), a...)
+}
 
-	// First, substitute all the color verbs
-	cformat, cargs, _, cerr := processColorVerbs(format, a...)
-	if cerr != nil {
-		return ""
-	}
+// golor.Fprintln is the counterpart of fmt.Fprintln. It formats its operands
+// using the default formats as fmt.Fprintln does and writes them to the given
+// writer. It returns the number of bytes written and any write error
+// encountered.
+func Fprintln(w io.Writer, a ...any) (n int, err error) {
+	return Fprintf(w, operandsFormat(true, a...), a...)
+}
 
-	// Let fmt.Sprintf do the rest of the job
-	return fmt.Sprintf(cformat, cargs...)
+// golor.Errorf is the counterpart of fmt.Errorf. It substitutes the color verbs
+// (%C{...}) and all the other verbs exactly as fmt.Errorf does. Hence, the verb
+// %w can be used to wrap errors as with fmt.Errorf, even inside color verbs.
+// Problems with the format string or the arguments are reported with markers in
+// the message of the error, as in golor.Sprintf
+func Errorf(format string, a ...any) error {
+
+	p := newPrinter()
+	p.wrapErrs = true
+	p.doPrintf(parseCached(format), a)
+	err := p.wrapError(a)
+	p.free()
+	return err
 }
 
-// golor.Fprintf is the counterpart of fmt.Fprintf. It just substitutes the
-// color verbs (%C{...}) and queries fmt.Fprintf to substitute the rest and to
-// write them in the given writer. It returns the number of bytes written and
-// any write error encountered.
-//
-// It panics if the first argument after the writer is not a string
-func Fprintf(w io.Writer, a ...any) (n int, err error) {
+// golor.Append is the counterpart of fmt.Append. It formats its operands using
+// the default formats as fmt.Append does, appends the result to the byte slice
+// and returns the updated slice.
+func Append(b []byte, a ...any) []byte {
+	return Appendf(b, operandsFormat(false, a...), a...)
+}
 
-	// First, substitute all the color verbs. Note that the function panics if
-	// the first argument after the writer is not a string
-	cformat, cargs, _, cerr := processColorVerbs(a[0].(string), a[1:]...)
-	if cerr != nil {
-		return 0, err
-	}
+// golor.Appendf is the counterpart of fmt.Appendf. It substitutes the color
+// verbs (%C{...}) and all the other verbs exactly as fmt.Appendf does, and
+// appends the result to the byte slice. It returns the updated slice. Problems
+// with the format string or the arguments are reported with markers as in
+// golor.Sprintf
+func Appendf(b []byte, format string, a ...any) []byte {
+
+	p := newPrinter()
+	p.doPrintf(parseCached(format), a)
+	b = append(b, p.buf...)
+	p.free()
+	return b
+}
 
-	// Let fmt.Fprintf do the rest of the job
-	return fmt.Fprintf(w, cformat, cargs...)
+// golor.Appendln is the counterpart of fmt.Appendln. It formats its operands
+// using the default formats as fmt.Appendln does, appends the result to the
+// byte slice and returns the updated slice.
+func Appendln(b []byte, a ...any) []byte {
+	return Appendf(b, operandsFormat(true, a...), a...)
 }
 
 // Local Variables:
@@ -0,0 +1,276 @@
+// -*- coding: utf-8 -*-
+// markup.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 02:23:51.870114526 (1792203831)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following markers are inserted in the output of markup strings when a
+// tag is not valid, when a closing tag does not match the last tag opened, or
+// when a tag is never closed. They are used as format strings for inserting
+// the offending tag
+const (
+	badtag_marker     = "%%!(BADTAG=%s)"
+	unbalanced_marker = "%%!(UNBALANCED=%s)"
+	notclosed_marker  = "%%!(NOCLOSE=%s)"
+)
+
+// Names of the tags used for setting the foreground and background colors
+const (
+	fg_tag = "fg"
+	bg_tag = "bg"
+)
+
+// Characters that can be escaped in markup strings with the escape character
+const markup_escaped_chars = "<\\"
+
+// Types
+// ----------------------------------------------------------------------------
+
+// An element of a markup string which is still open while parsing it, i.e.,
+// the contents of a tag whose closing tag has not been found yet. name is the
+// name of the tag (which is normalized so that it can be closed with any of
+// its aliases), text is the whole tag and offset its location
+type element struct {
+	name     string
+	text     string
+	offset   int
+	effect   rendition
+	children []node
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Short names of the properties which can be used as tags in markup strings
+var tagAliases = map[string]string{
+	"b": "bold",
+	"i": "italic",
+	"u": "underline",
+	"s": "strike",
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the rendition of the given opening tag, without the angle brackets,
+// along with its name. It returns an error if the tag is not understood, but
+// its name is returned anyway so that it can be matched with its closing tag
+func parseTag(tag string) (name string, r rendition, err error) {
+
+	words := strings.Fields(strings.ToLower(tag))
+	if len(words) == 0 {
+		return "", rendition{}, fmt.Errorf("%w: %q", ErrInvalidTag, "<"+tag+">")
+	}
+	name = closingTagName(words[0])
+
+	// Tags are either properties, or colors given with fg and bg, which
+	// might be written as in textual color specifications
+	switch {
+	case name == fg_tag || name == bg_tag:
+		c, cerr := parseColorWord(strings.Join(words[1:], " "))
+		if len(words) < 2 || cerr != nil {
+			return name, rendition{}, fmt.Errorf("%w: %q", ErrInvalidTag, "<"+tag+">")
+		}
+		if name == fg_tag {
+			r.fg = c
+		} else {
+			r.bg = c
+		}
+
+	case len(words) == 1 && propertyNames[name] != 0:
+		r.properties = propertyNames[name]
+
+	default:
+		return name, rendition{}, fmt.Errorf("%w: %q", ErrInvalidTag, "<"+tag+">")
+	}
+
+	return
+}
+
+// Return the name of the given tag, without the angle brackets and the slash,
+// once aliases are substituted, so that opening and closing tags can be
+// compared
+func closingTagName(tag string) string {
+
+	name := strings.ToLower(strings.TrimSpace(tag))
+	if alias, ok := tagAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// Return the literal text which starts at the i-th position of the markup
+// string and ends right before the next tag, with all escape sequences
+// substituted by the characters they stand for. It returns the text and the
+// position after it
+func parseMarkupText(markup string, i int) (text string, newi int) {
+
+	var escaped []byte
+	start := i
+	for i < len(markup) && markup[i] != '<' {
+
+		if markup[i] == escape_char && i+1 < len(markup) && strings.IndexByte(markup_escaped_chars, markup[i+1]) >= 0 {
+			escaped = append(escaped, markup[start:i]...)
+			start = i + 1
+			i += 2
+			continue
+		}
+		i++
+	}
+
+	if escaped == nil {
+		return markup[start:i], i
+	}
+	return string(append(escaped, markup[start:i]...)), i
+}
+
+// Parse the given markup string and return its nodes, which consist only of
+// text and colored text. Problems are shown with markers in the nodes, and
+// the first one is also returned as an error of type *FormatError
+func parseMarkup(markup string) (nodes []node, err error) {
+
+	// The first error found is the one returned
+	setError := func(offset int, e error) {
+		if err == nil {
+			err = &FormatError{Offset: offset, Err: e}
+		}
+	}
+
+	// The first element is the whole markup string, and it is never closed
+	stack := []element{{}}
+	for i := 0; i < len(markup); {
+
+		top := &stack[len(stack)-1]
+		start := i
+		var text string
+		if text, i = parseMarkupText(markup, i); i > start {
+			top.children = append(top.children, node{kind: textNode, offset: start, text: text})
+		}
+		if i >= len(markup) {
+			break
+		}
+
+		// Get the whole tag. If it is never closed, it is taken as an invalid
+		// tag which lasts until the end of the markup string
+		start = i
+		end := strings.IndexByte(markup[i:], '>')
+		if end < 0 {
+			e := fmt.Errorf("%w: %q", ErrInvalidTag, markup[i:])
+			setError(start, e)
+			top.children = append(top.children, node{kind: textNode, offset: start, text: fmt.Sprintf(badtag_marker, markup[i:])})
+			break
+		}
+		tag := markup[i : i+end+1]
+		i += end + 1
+
+		// Closing tags must match the last tag opened. Otherwise, they are
+		// ignored
+		if strings.HasPrefix(tag, "</") {
+			if len(stack) == 1 || closingTagName(tag[2:len(tag)-1]) != top.name {
+				setError(start, fmt.Errorf("%w: %q", ErrUnbalancedTag, tag))
+				top.children = append(top.children, node{kind: textNode, offset: start, text: fmt.Sprintf(unbalanced_marker, tag)})
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			parent := &stack[len(stack)-1]
+			parent.children = append(parent.children, node{kind: inlineNode, offset: top.offset, text: markup[top.offset:i], children: top.children, effect: top.effect})
+			continue
+		}
+
+		// Opening tags start a new element. If they are not valid, a marker
+		// is shown and the element is created anyway with no effect so that
+		// its closing tag is still matched
+		name, effect, e := parseTag(tag[1 : len(tag)-1])
+		if e != nil {
+			setError(start, e)
+			top.children = append(top.children, node{kind: textNode, offset: start, text: fmt.Sprintf(badtag_marker, tag)})
+		}
+		stack = append(stack, element{name: name, text: tag, offset: start, effect: effect})
+	}
+
+	// All the elements which are still open are closed at the end of the
+	// markup string, and a marker is shown right before them
+	for len(stack) > 1 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		setError(top.offset, fmt.Errorf("%w: %q", ErrUnbalancedTag, top.text))
+		parent := &stack[len(stack)-1]
+		parent.children = append(parent.children,
+			node{kind: textNode, offset: top.offset, text: fmt.Sprintf(notclosed_marker, top.text)},
+			node{kind: inlineNode, offset: top.offset, text: markup[top.offset:], children: top.children, effect: top.effect})
+	}
+
+	return stack[0].children, err
+}
+
+// golor.Printm shows the given markup string on the standard output. Markup
+// strings contain text with tags such as <b>...</b> or <fg red>...</fg>, which
+// are rendered with the same escape sequences used for color verbs. It
+// returns the number of bytes written and any write error encountered. If
+// there are problems with the tags, the output is written anyway with markers
+// describing them, e.g., %!(UNBALANCED=</b>), and the first one is returned as
+// an error of type *FormatError unless a write error happens
+func Printm(markup string) (n int, err error) {
+	return Fprintm(os.Stdout, markup)
+}
+
+// golor.Sprintm renders the given markup string as golor.Printm does and
+// returns the resulting string. If there are problems with the tags, the
+// resulting string contains markers describing them, which do not tell where
+// they were found. Use golor.CheckMarkup for locating them
+func Sprintm(markup string) string {
+
+	nodes, _ := parseMarkup(markup)
+	p := newPrinter()
+	p.printNodes(nodes, nil)
+	p.sync()
+	s := string(p.buf)
+	p.free()
+	return s
+}
+
+// golor.CheckMarkup returns the first problem found with the tags of the given
+// markup string as an error of type *FormatError, whose offset is the position
+// (in bytes) of the offending tag, or nil if there is none
+func CheckMarkup(markup string) error {
+
+	_, err := parseMarkup(markup)
+	return err
+}
+
+// golor.Fprintm renders the given markup string as golor.Printm does and
+// writes the result to the given writer. It returns the number of bytes written
+// and any write error encountered. Problems with the tags are reported as in
+// golor.Printm
+func Fprintm(w io.Writer, markup string) (n int, err error) {
+
+	nodes, perr := parseMarkup(markup)
+	p := newPrinter()
+	p.setWriter(w)
+	p.printNodes(nodes, nil)
+	p.sync()
+	if n, err = w.Write(p.buf); err == nil {
+		err = perr
+	}
+	p.free()
+	return
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,73 @@
+// -*- coding: utf-8 -*-
+// markup_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 13:05:19.402817736 (1792242319)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"errors"
+	"testing"
+)
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Markup strings are rendered with the escape sequences of their tags, and
+// problems are shown with markers and reported with the offset of the first
+// offending tag
+func TestMarkup(t *testing.T) {
+
+	for _, test := range []struct {
+		markup string
+		want   string
+		err    error
+		offset int
+	}{
+
+		// nesting and aliases
+		{"<b>bold <i>both</i> bold</b>", "\x1b[1mbold \x1b[3mboth\x1b[23m bold\x1b[0m", nil, 0},
+		{"<b>x</bold>", "\x1b[1mx\x1b[0m", nil, 0},
+		{"<bold>x</b>", "\x1b[1mx\x1b[0m", nil, 0},
+		{"<u>x</underline>", "\x1b[4mx\x1b[0m", nil, 0},
+		{"<fg red>x <bg navy>y</bg></fg>", "\x1b[38;2;255;0;0mx \x1b[48;2;0;0;128my\x1b[0m", nil, 0},
+
+		// escape sequences
+		{`\<b> \\ x`, `<b> \ x`, nil, 0},
+		{`<b>\<i></b>`, "\x1b[1m<i>\x1b[0m", nil, 0},
+		{`a\b`, `a\b`, nil, 0},
+
+		// unclosed, mismatched and invalid tags
+		{"<b>x", "%!(NOCLOSE=<b>)\x1b[1mx\x1b[0m", ErrUnbalancedTag, 0},
+		{"<b>x</i>y</b>", "\x1b[1mx%!(UNBALANCED=</i>)y\x1b[0m", ErrUnbalancedTag, 4},
+		{"x</b>", "x%!(UNBALANCED=</b>)", ErrUnbalancedTag, 1},
+		{"<b>a<i>b</b>c</i>", "%!(NOCLOSE=<b>)\x1b[1ma\x1b[3mb%!(UNBALANCED=</b>)c\x1b[0m", ErrUnbalancedTag, 8},
+		{"<foo>x</foo>", "%!(BADTAG=<foo>)x", ErrInvalidTag, 0},
+		{"<fg>x</fg>", "%!(BADTAG=<fg>)x", ErrInvalidTag, 0},
+		{"a <b", "a %!(BADTAG=<b)", ErrInvalidTag, 2},
+	} {
+		if got := Sprintm(test.markup); got != test.want {
+			t.Errorf("Sprintm(%q) = %q, want %q", test.markup, got, test.want)
+		}
+
+		err := CheckMarkup(test.markup)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("CheckMarkup(%q) = %v, want nil", test.markup, err)
+			}
+			continue
+		}
+		var ferr *FormatError
+		if !errors.Is(err, test.err) || !errors.As(err, &ferr) || ferr.Offset != test.offset {
+			t.Errorf("CheckMarkup(%q) = %v, want %v at offset %d", test.markup, err, test.err, test.offset)
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
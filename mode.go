@@ -0,0 +1,279 @@
+// -*- coding: utf-8 -*-
+// mode.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 07:02:15.571320948 (1792220535)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following constants are the color modes, which decide whether colors are
+// shown at all
+const (
+	ColorAuto   ColorMode = iota // colors are shown only if supported
+	ColorAlways                  // colors are always shown
+	ColorNever                   // colors are never shown
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A ColorMode decides whether colors are shown at all, as the conventional
+// command line option --color=auto|always|never. With ColorAuto, colors are
+// shown only if they are supported by the output (see DetectColorProfile) and
+// not disabled with the environment variables NO_COLOR or CLICOLOR, or
+// forced with FORCE_COLOR or CLICOLOR_FORCE. With ColorAlways, colors are shown
+// even if the output is not a terminal, and with ColorNever, verbs are
+// substituted but no escape sequences are issued at all. It implements
+// flag.Value, so that it can be given in the command line:
+//
+//	var mode golor.ColorMode
+//	flag.Var(&mode, "color", "when to show colors: auto, always or never")
+type ColorMode int
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The color mode used for all writers, unless another one is set for them
+var colorMode atomic.Int32
+
+// The color modes set for specific writers
+var writerColorModes sync.Map
+
+// Whether all values of the types of the writers used so far can be compared
+var comparableWriterTypes sync.Map
+
+// The color mode and the color profile given in the environment variables,
+// which are read only once, the first time they are needed
+var envColorMode = sync.OnceValues(func() (ColorMode, ColorProfile) {
+
+	// NO_COLOR disables colors whatever its value, unless it is empty
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNever, Ascii
+	}
+
+	// FORCE_COLOR might also give the color profile with its level
+	if level, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		if level == "0" || strings.EqualFold(level, "false") {
+			return ColorNever, Ascii
+		}
+		return ColorAlways, forcedColorProfile()
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return ColorAlways, forcedColorProfile()
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return ColorNever, Ascii
+	}
+
+	return ColorAuto, TrueColor
+})
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the color profile used when colors are always shown, even if the
+// output is not a terminal. It is the one given with the level of FORCE_COLOR,
+// either 1 (ANSI16), 2 (ANSI256) or 3 (TrueColor), or the one described by the
+// terminal, or the basic ANSI palette otherwise
+func forcedColorProfile() ColorProfile {
+
+	switch os.Getenv("FORCE_COLOR") {
+	case "1":
+		return ANSI16
+	case "2":
+		return ANSI256
+	case "3":
+		return TrueColor
+	}
+	if profile, ok := envTermColorProfile(); ok && profile != Ascii {
+		return profile
+	}
+	return ANSI16
+}
+
+// SetColorMode sets the color mode used for all writers, and also when no
+// writer is used at all, e.g., by golor.Sprintf. By default, it is ColorAuto
+func SetColorMode(mode ColorMode) {
+	colorMode.Store(int32(mode))
+}
+
+// CurrentColorMode returns the color mode used for all writers
+func CurrentColorMode() ColorMode {
+	return ColorMode(colorMode.Load())
+}
+
+// SetWriterColorMode sets the color mode used for the given writer, e.g.,
+// os.Stderr, which takes precedence over the one used for all writers.
+// ColorAuto removes it, so that the color mode used for all writers is used
+// again. The writer must be comparable, e.g., a pointer, and otherwise it
+// returns an error wrapping ErrUncomparableWriter
+func SetWriterColorMode(w io.Writer, mode ColorMode) error {
+
+	if !comparableWriter(w) {
+		return fmt.Errorf("golor: %w: %T", ErrUncomparableWriter, w)
+	}
+	if mode == ColorAuto {
+		writerColorModes.Delete(w)
+		return nil
+	}
+	writerColorModes.Store(w, mode)
+	return nil
+}
+
+// Return true if all values of the given type can be compared, i.e., if it is
+// comparable and it contains no interfaces, whose dynamic values might not be
+// comparable
+func comparableType(t reflect.Type) bool {
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return comparableType(t.Elem())
+	case reflect.Struct:
+		for idx := range t.NumField() {
+			if !comparableType(t.Field(idx).Type) {
+				return false
+			}
+		}
+	}
+	return t.Comparable()
+}
+
+// Return true if the given writer can be compared, so that it can be used as
+// a key of writerColorModes. Writers are usually pointers, which are checked
+// first. Otherwise, the types of writers are checked only once, and only the
+// values of those which contain interfaces have to be checked
+func comparableWriter(w io.Writer) bool {
+
+	if w == nil {
+		return false
+	}
+	t := reflect.TypeOf(w)
+	if t.Kind() == reflect.Pointer {
+		return true
+	}
+	all, ok := comparableWriterTypes.Load(t)
+	if !ok {
+		all, _ = comparableWriterTypes.LoadOrStore(t, comparableType(t))
+	}
+	if all.(bool) {
+		return true
+	}
+	return t.Comparable() && reflect.ValueOf(w).Comparable()
+}
+
+// Return the color mode used for the given writer, which is nil when no writer
+// is used at all. Writers which can not be compared are shown with the color
+// mode used for all writers
+func writerColorMode(w io.Writer) ColorMode {
+
+	if comparableWriter(w) {
+		if mode, ok := writerColorModes.Load(w); ok {
+			return mode.(ColorMode)
+		}
+	}
+	return CurrentColorMode()
+}
+
+// Return the color profile used for writing to the given writer, or when no
+// writer is used at all if it is nil. No colors are shown with ColorNever.
+// Otherwise, the color profile set with SetColorProfile is used, if any. If
+// colors are always shown, the profile is the one given in the environment,
+// and otherwise, it is detected only once for every file, and TrueColor is used
+// for any other writer, e.g., buffers
+func writerColorProfile(w io.Writer) ColorProfile {
+
+	mode, profile := writerColorMode(w), TrueColor
+	if mode == ColorAuto {
+		mode, profile = envColorMode()
+	} else if mode == ColorAlways {
+		profile = forcedColorProfile()
+	}
+	if mode == ColorNever {
+		return Ascii
+	}
+	if explicit, ok := explicitColorProfile(); ok {
+		return explicit
+	}
+	if mode == ColorAlways {
+		return profile
+	}
+
+	// Otherwise, colors are shown only if they are supported
+	f, ok := w.(*os.File)
+	if !ok {
+		return TrueColor
+	}
+	switch f {
+	case os.Stdout:
+		return stdoutColorProfile()
+	case os.Stderr:
+		return stderrColorProfile()
+	}
+
+	return fileColorProfile(f)
+}
+
+// Return whether styles and colors of underlines are shown when writing to the
+// given writer, or when no writer is used at all if it is nil. They are shown
+// only if the terminal supports them when writing to files or if colors are
+// always shown, and they are always shown otherwise, e.g., in strings
+func writerUnderlines(w io.Writer) bool {
+
+	if _, ok := w.(*os.File); ok || writerColorMode(w) == ColorAlways {
+		return envUnderlines()
+	}
+	return true
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the name of the color mode, as accepted by Set
+func (mode ColorMode) String() string {
+
+	switch mode {
+	case ColorAuto:
+		return "auto"
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	}
+	return "unknown"
+}
+
+// Set the color mode from its name, either auto, always or never, which is
+// not case sensitive. It returns an error wrapping ErrInvalidColorMode if the
+// name is not recognized
+func (mode *ColorMode) Set(name string) error {
+
+	for m := ColorAuto; m <= ColorNever; m++ {
+		if strings.EqualFold(name, m.String()) {
+			*mode = m
+			return nil
+		}
+	}
+	return fmt.Errorf("golor: %w: %q", ErrInvalidColorMode, name)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,156 @@
+// -*- coding: utf-8 -*-
+// mode_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 11:52:40.118370625 (1792237960)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A writer whose values can not be compared, because it contains a slice
+type sliceWriter struct {
+	chunks [][]byte
+}
+
+// A writer whose values can be compared only if the value they contain can be
+// compared
+type anyWriter struct {
+	value any
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Discard the given bytes
+func (w sliceWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Discard the given bytes
+func (w anyWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Writers which can not be compared are accepted by Fprintf and Fprintm, but
+// no color mode can be set for them
+func TestUncomparableWriter(t *testing.T) {
+
+	w := sliceWriter{chunks: [][]byte{nil}}
+	if _, err := Fprintf(w, "%C{x}", Ansi16(1)); err != nil {
+		t.Errorf("Fprintf(sliceWriter) returned %v", err)
+	}
+	if _, err := Fprintm(w, "<b>x</b>"); err != nil {
+		t.Errorf("Fprintm(sliceWriter) returned %v", err)
+	}
+	if _, err := MustCompile("%C{x}").Fprintf(w, Ansi16(1)); err != nil {
+		t.Errorf("Format.Fprintf(sliceWriter) returned %v", err)
+	}
+	if err := SetWriterColorMode(w, ColorNever); !errors.Is(err, ErrUncomparableWriter) {
+		t.Errorf("SetWriterColorMode(sliceWriter) = %v, want %v", err, ErrUncomparableWriter)
+	}
+
+	// Values of the same type might be comparable or not
+	if _, err := Fprintf(anyWriter{value: []int{1}}, "%C{x}", Ansi16(1)); err != nil {
+		t.Errorf("Fprintf(anyWriter) returned %v", err)
+	}
+	if err := SetWriterColorMode(anyWriter{value: []int{1}}, ColorNever); !errors.Is(err, ErrUncomparableWriter) {
+		t.Errorf("SetWriterColorMode(anyWriter) = %v, want %v", err, ErrUncomparableWriter)
+	}
+	if err := SetWriterColorMode(anyWriter{value: 1}, ColorNever); err != nil {
+		t.Errorf("SetWriterColorMode(anyWriter) = %v, want nil", err)
+	}
+	SetWriterColorMode(anyWriter{value: 1}, ColorAuto)
+}
+
+// Color modes set for specific writers take precedence over the one used for
+// all writers, until they are removed with ColorAuto
+func TestWriterColorMode(t *testing.T) {
+
+	var buf bytes.Buffer
+	if err := SetWriterColorMode(&buf, ColorNever); err != nil {
+		t.Fatalf("SetWriterColorMode(&buf) = %v", err)
+	}
+	Fprintf(&buf, "%C{x}", Ansi16(1))
+	if got := buf.String(); got != "x" {
+		t.Errorf("Fprintf with ColorNever = %q, want %q", got, "x")
+	}
+
+	buf.Reset()
+	if err := SetWriterColorMode(&buf, ColorAuto); err != nil {
+		t.Fatalf("SetWriterColorMode(&buf) = %v", err)
+	}
+	Fprintf(&buf, "%C{x}", Ansi16(1))
+	if got, want := buf.String(), "\x1b[31mx\x1b[0m"; got != want {
+		t.Errorf("Fprintf with ColorAuto = %q, want %q", got, want)
+	}
+}
+
+// The level given in FORCE_COLOR decides the color profile used when colors
+// are always shown
+func TestForcedColorProfile(t *testing.T) {
+
+	for _, test := range []struct {
+		level string
+		want  ColorProfile
+	}{
+		{"1", ANSI16},
+		{"2", ANSI256},
+		{"3", TrueColor},
+	} {
+		t.Setenv("FORCE_COLOR", test.level)
+		if got := forcedColorProfile(); got != test.want {
+			t.Errorf("forcedColorProfile() with FORCE_COLOR=%s = %v, want %v", test.level, got, test.want)
+		}
+	}
+}
+
+// The color profile of files is detected only once, so that writing to them
+// does not allocate at all, and every file is detected on its own
+func TestFileColorProfile(t *testing.T) {
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := DetectColorProfile(f)
+	if got := writerColorProfile(f); got != want {
+		t.Errorf("writerColorProfile(f) = %v, want %v", got, want)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { writerColorProfile(f) }); allocs != 0 {
+		t.Errorf("writerColorProfile(f) allocates %v times, want 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { Fprintf(f, "%C{%d}", Ansi16(1), 1) }); allocs != 0 {
+		t.Errorf("Fprintf(f) allocates %v times, want 0", allocs)
+	}
+
+	g, err := os.Create(filepath.Join(t.TempDir(), "other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if got := writerColorProfile(g); got != want {
+		t.Errorf("writerColorProfile(g) = %v, want %v", got, want)
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,451 @@
+// -*- coding: utf-8 -*-
+// parser.go
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 23:18:07.640215377 (1792192687)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// Flags accepted by the verbs of the Printf family
+const verb_flags = "#0+- "
+
+// Verb used for representing the end of the format string when a verb is
+// expected, i.e., when the format string ends with a percent sign or with the
+// flags, width or precision of a verb
+const no_verb = -1
+
+// Inside color verbs, the escape character can be used for writing a literal
+// closing brace, or the escape character itself
+const (
+	escape_char   = '\\'
+	escaped_chars = "}\\"
+)
+
+// The following constants distinguish the different kinds of nodes that result
+// from parsing a format string
+const (
+	textNode      = iota // literal text
+	verbNode             // a verb of the Printf family
+	colorNode            // a color verb %C{...}
+	inlineNode           // a color verb with an inline specification %C(...){...}
+	malformedNode        // a color verb which is never closed
+)
+
+// Color verbs whose effect is given as an argument are followed by this brace,
+// whereas inline color verbs are followed by a parenthesis
+const effect_brace = '{'
+
+// Maximum number of format strings kept in the cache of formats
+const max_cached_formats = 1024
+
+// Types
+// ----------------------------------------------------------------------------
+
+// An argument index, e.g., [2], as written in a verb of the Printf family.
+// present is true only if an index was given, and ok is true only if it was
+// correctly written. In that case, n is the index, which starts at 1
+type argIndex struct {
+	present, ok bool
+	n           int
+}
+
+// Description of a verb of the Printf family. It follows exactly the grammar
+// of verbs in fmt, i.e., a percent sign followed by any number of flags, an
+// optional width (which might be given as an argument with '*'), an optional
+// precision (also with '*'), and the verb, where explicit argument indexes can
+// be given before the width, the precision and the verb
+type verbSpec struct {
+	flags string
+
+	widthIndex   argIndex
+	widthStar    bool
+	width        int
+	widthPresent bool
+
+	dot          bool
+	precIndex    argIndex
+	precStar     bool
+	prec         int
+	precPresent  bool
+	verbIndex    argIndex
+	badArgNumber bool
+
+	verb rune
+
+	// Format given to fmt for substituting this verb, if the width and
+	// precision are not given as arguments
+	format string
+}
+
+// A node of a parsed format string. Text nodes contain only literal text, verb
+// nodes contain a verb of the Printf family, and color nodes contain the nodes
+// of the text enclosed by a color verb, whose spec might contain an explicit
+// argument index. Inline nodes are color verbs whose effect is given in the
+// format string, and it is stored in effect, unless it is not correctly
+// written, in which case err describes the problem. Malformed nodes keep the
+// spec of the color verb which is never closed. In all cases, text is the
+// chunk of the format string that resulted in the node (though escape sequences
+// are already substituted in text nodes), and offset its location
+type node struct {
+	kind     int
+	offset   int
+	text     string
+	spec     verbSpec
+	children []node
+	effect   rendition
+	err      error
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The functions of the Printf family keep the nodes of the format strings
+// they parse in a cache, so that the same format string is parsed only once.
+// Because format strings might be computed at run time, the cache is bounded
+var (
+	cacheMutex sync.RWMutex
+	cache      = make(map[string][]node)
+)
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return true if the given number is too large to be used as a width or a
+// precision, as done by fmt
+func tooLarge(x int) bool {
+	const max int = 1e6
+	return x > max || x < -max
+}
+
+// Parse a number in the given string between the start and end positions. It
+// returns the number, whether there was a number at all, and the position
+// after it. As in fmt, if the number is too large, the whole string until the
+// end is consumed
+func parseNum(s string, start, end int) (num int, isnum bool, newi int) {
+
+	if start >= end {
+		return 0, false, end
+	}
+	for newi = start; newi < end && '0' <= s[newi] && s[newi] <= '9'; newi++ {
+		if tooLarge(num) {
+			return 0, false, end
+		}
+		num = num*10 + int(s[newi]-'0')
+		isnum = true
+	}
+
+	return
+}
+
+// Parse the argument index given at the beginning of the string, if any. It
+// returns the index, and the number of bytes consumed
+func parseArgNumber(format string) (index argIndex, wid int) {
+
+	// There must be at least 3 bytes: left bracket, number and right bracket
+	index.present = true
+	if len(format) < 3 {
+		return index, 1
+	}
+
+	// Find the right bracket
+	for i := 1; i < len(format); i++ {
+		if format[i] == ']' {
+			n, ok, newi := parseNum(format, 1, i)
+			if !ok || newi != i {
+				return index, i + 1
+			}
+			index.ok, index.n = true, n
+			return index, i + 1
+		}
+	}
+
+	// There is no right bracket
+	return index, 1
+}
+
+// Parse the argument index, if any, given at the i-th position of the format
+// string. It returns the index, the position after it and whether an index was
+// correctly written
+func parseArgIndex(format string, i int) (index argIndex, newi int, found bool) {
+
+	if len(format) <= i || format[i] != '[' {
+		return argIndex{}, i, false
+	}
+	index, wid := parseArgNumber(format[i:])
+
+	return index, i + wid, index.ok
+}
+
+// Parse the verb of the Printf family which starts at the i-th position of the
+// format string, where a percent sign is expected, and return its description
+// along with the position after it
+func parseVerb(format string, i int) (spec verbSpec, newi int) {
+
+	end := len(format)
+
+	// Skip the percent sign and get the flags
+	i++
+	start := i
+	for i < end && strings.IndexByte(verb_flags, format[i]) >= 0 {
+		i++
+	}
+	spec.flags = format[start:i]
+
+	// Do we have an explicit argument index?
+	var afterIndex bool
+	spec.widthIndex, i, afterIndex = parseArgIndex(format, i)
+
+	// Do we have width?
+	if i < end && format[i] == '*' {
+		spec.widthStar = true
+		i++
+		afterIndex = false
+	} else {
+		spec.width, spec.widthPresent, i = parseNum(format, i, end)
+
+		// An argument index followed by a width, e.g., %[3]2d, is not
+		// allowed
+		if afterIndex && spec.widthPresent {
+			spec.badArgNumber = true
+		}
+	}
+
+	// Do we have precision?
+	if i+1 < end && format[i] == '.' {
+		spec.dot = true
+		i++
+
+		// An argument index followed by a precision, e.g., %[3].2d, is not
+		// allowed either
+		if afterIndex {
+			spec.badArgNumber = true
+		}
+		spec.precIndex, i, afterIndex = parseArgIndex(format, i)
+		if i < end && format[i] == '*' {
+			spec.precStar = true
+			i++
+			afterIndex = false
+		} else {
+			spec.prec, spec.precPresent, i = parseNum(format, i, end)
+			if !spec.precPresent {
+				spec.prec, spec.precPresent = 0, true
+			}
+		}
+	}
+
+	// Finally, an explicit argument index might be given right before the
+	// verb
+	if !afterIndex {
+		spec.verbIndex, i, _ = parseArgIndex(format, i)
+	}
+
+	// Get the verb
+	if i >= end {
+		spec.verb = no_verb
+		return spec, i
+	}
+	verb, size := utf8.DecodeRuneInString(format[i:])
+	spec.verb = verb
+	if !spec.widthStar && !spec.precStar {
+		spec.format = simpleVerb(spec.flags, spec.width, spec.widthPresent, spec.prec, spec.precPresent, fmtVerb(verb))
+	}
+
+	return spec, i + size
+}
+
+// Return true if the given verb, which ends right before newi, is the
+// beginning of a color verb, i.e., %C{ or %[n]C{ when an explicit argument
+// index is given. Color verbs accept a width and a precision, but the only flag
+// allowed is '-'
+func isColorVerb(format string, spec verbSpec, newi int) bool {
+	return spec.verb == 'C' && newi < len(format) && format[newi] == '{' &&
+		strings.Trim(spec.flags, "-") == ""
+}
+
+// Return true if the given verb, which ends right before newi, is the
+// beginning of a color verb with an inline specification, i.e., %C(. Because
+// the effect is not given as an argument, neither explicit argument indexes
+// nor widths or precisions given with '*' are allowed
+func isInlineColorVerb(format string, spec verbSpec, newi int) bool {
+	return spec.verb == 'C' && newi < len(format) && format[newi] == '(' &&
+		strings.Trim(spec.flags, "-") == "" && !spec.widthIndex.present && !spec.widthStar &&
+		!spec.precIndex.present && !spec.precStar && !spec.verbIndex.present
+}
+
+// Return the position of the parenthesis closing the inline specification
+// which starts at the i-th position of the format string, right after the
+// opening one. Parentheses can be nested inside the specification. It returns
+// -1 if the specification is never closed
+func closingParen(format string, i int) int {
+
+	depth := 0
+	for ; i < len(format); i++ {
+		switch format[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// Return the literal text which starts at the i-th position of the format
+// string and ends right before the next verb or, if closed is true, right
+// before the closing brace of the color verb enclosing it. In the latter case,
+// escape sequences are substituted by the characters they stand for. It
+// returns the text and the position after it
+func parseText(format string, i int, closed bool) (text string, newi int) {
+
+	// The text is copied to a separate buffer only if it contains escape
+	// sequences
+	var escaped []byte
+	start := i
+	for i < len(format) && format[i] != '%' && !(closed && format[i] == '}') {
+
+		if closed && format[i] == escape_char && i+1 < len(format) && strings.IndexByte(escaped_chars, format[i+1]) >= 0 {
+
+			// Copy the text before the escape character and skip it. The
+			// character escaped is copied with the next chunk
+			escaped = append(escaped, format[start:i]...)
+			start = i + 1
+			i += 2
+			continue
+		}
+		i++
+	}
+
+	if escaped == nil {
+		return format[start:i], i
+	}
+	return string(append(escaped, format[start:i]...)), i
+}
+
+// Parse the format string from the i-th position. If closed is true, then this
+// is the text enclosed in a color verb, and the parsing stops at its closing
+// brace. It returns the nodes found, the position after the last byte parsed
+// and, if closed is true, whether the closing brace was found
+func parseNodes(format string, i int, closed bool) (nodes []node, newi int, found bool) {
+
+	for i < len(format) {
+
+		// Get the literal text until the next verb or, if this is the text
+		// of a color verb, its closing brace
+		start := i
+		var text string
+		if text, i = parseText(format, i, closed); i > start {
+			nodes = append(nodes, node{kind: textNode, offset: start, text: text})
+		}
+		if i >= len(format) {
+			break
+		}
+
+		// In case this is the closing brace, return immediately
+		if format[i] == '}' {
+			return nodes, i + 1, true
+		}
+
+		// Otherwise, this is a verb. Check whether it is a color verb
+		spec, next := parseVerb(format, i)
+		if isColorVerb(format, spec, next) {
+
+			// Parse the enclosed text. If the color verb is never closed,
+			// then the rest of the format string is parsed as if the color
+			// verb did not exist
+			children, after, ok := parseNodes(format, next+1, true)
+			if !ok {
+				nodes = append(nodes, node{kind: malformedNode, offset: i, text: format[i : next+1], spec: spec})
+				i = next + 1
+				continue
+			}
+			nodes = append(nodes, node{kind: colorNode, offset: i, text: format[i:after], spec: spec, children: children})
+			i = after
+			continue
+		}
+
+		// Inline specifications are parsed right now, and they must be
+		// followed by the text of the color verb. Otherwise, the rest of the
+		// format string is parsed as if the color verb did not exist
+		if isInlineColorVerb(format, spec, next) {
+
+			end := closingParen(format, next+1)
+			var children []node
+			var after int
+			ok := end >= 0 && end+1 < len(format) && format[end+1] == '{'
+			if ok {
+				children, after, ok = parseNodes(format, end+2, true)
+			}
+			if !ok {
+				nodes = append(nodes, node{kind: malformedNode, offset: i, text: format[i : next+1], spec: spec})
+				i = next + 1
+				continue
+			}
+			effect, err := parseSpec(format[next+1 : end])
+			nodes = append(nodes, node{kind: inlineNode, offset: i, text: format[i:after], spec: spec, children: children, effect: effect, err: err})
+			i = after
+			continue
+		}
+
+		// This is a verb of the Printf family
+		nodes = append(nodes, node{kind: verbNode, offset: i, text: format[i:next], spec: spec})
+		i = next
+	}
+
+	return nodes, i, false
+}
+
+// Return true if this node consumes an argument with its effect, i.e., if it
+// is a color verb whose effect is not given inline, even if it is never closed
+func (n *node) effectArg() bool {
+	return n.kind == colorNode || (n.kind == malformedNode && n.text[len(n.text)-1] == effect_brace)
+}
+
+// Parse the given format string and return its nodes
+func parse(format string) []node {
+
+	nodes, _, _ := parseNodes(format, 0, false)
+	return nodes
+}
+
+// Return the nodes of the given format string, parsing it only if it is not
+// found in the cache of formats. Nodes are never modified once created, so
+// that they can be safely shared
+func parseCached(format string) []node {
+
+	cacheMutex.RLock()
+	nodes, ok := cache[format]
+	cacheMutex.RUnlock()
+	if ok {
+		return nodes
+	}
+
+	// Parse the format string and store its nodes unless the cache is full
+	nodes = parse(format)
+	cacheMutex.Lock()
+	if len(cache) < max_cached_formats {
+		cache[format] = nodes
+	}
+	cacheMutex.Unlock()
+
+	return nodes
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,171 @@
+// -*- coding: utf-8 -*-
+// parser_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 10:12:31.284905117 (1792231951)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A format string of the Printf family, without color verbs, along with its
+// arguments
+type fmtCase struct {
+	format string
+	args   []any
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Formats whose substitution must be exactly the same as the one computed by
+// fmt, including the markers issued for malformed verbs and wrong arguments
+var fmtCases = []fmtCase{
+
+	// plain text and verbs
+	{"", nil},
+	{"plain text", nil},
+	{"%d %s %v %q", []any{42, "str", 3.5, "quoted"}},
+	{"%x %X %o %O %b %c %U", []any{255, 255, 8, 8, 5, 'a', 0x1f600}},
+	{"%e %E %f %F %g %G", []any{1e6, 1e6, 3.14159, 3.14159, 1e-7, 1e21}},
+	{"%t %p", []any{true, nil}},
+	{"%T %T %T", []any{1, "a", []int{1}}},
+	{"%v %+v %#v", []any{struct{ A int }{1}, struct{ A int }{1}, struct{ A int }{1}}},
+	{"%s", []any{errors.New("an error")}},
+	{"ünicode %s ✓", []any{"ñ"}},
+
+	// flags, width and precision
+	{"%+d % d %05d %-5d| %x %#x % x", []any{3, 3, 3, 3, "hi", 255, "hi"}},
+	{"%8.3f|%-8.3f|%+.2e", []any{3.14159, 3.14159, 12345.678}},
+	{"%10s|%-10s|%.2s|%5.1s|", []any{"abc", "abc", "abc", "abc"}},
+	{"%#v %+q %#q", []any{[]string{"a"}, "ñ", "a`b"}},
+	{"%0-5d|%-05d|", []any{7, 7}},
+	{"%.0d|%.d|", []any{0, 0}},
+
+	// width and precision given as arguments
+	{"%*d|%-*d|%.*f|%*.*f|", []any{5, 1, 5, 1, 2, 3.14159, 8, 3, 3.14159}},
+	{"%*d|", []any{-5, 1}},
+	{"%.*f|", []any{-2, 3.14159}},
+	{"%*d", []any{"wide", 1}},
+	{"%.*d", []any{"prec", 1}},
+	{"%*d", []any{int8(4), 1}},
+	{"%*d", []any{uint(4), 1}},
+	{"%*d", []any{10000000, 1}},
+	{"%*d", []any{5}},
+	{"%.*d", nil},
+
+	// explicit argument indexes
+	{"%[2]d %[1]d", []any{1, 2}},
+	{"%[3]*.[2]*[1]f", []any{12.0, 2, 6}},
+	{"%d %d %#[1]x %#x", []any{16, 17}},
+	{"%[1]d %[1]x %[1]o", []any{10}},
+	{"%[2]*[1]d", []any{3, 5}},
+	{"%.[2]d", []any{1, 2}},
+	{"%[1]d %d", []any{1, 2, 3}},
+
+	// bad argument indexes
+	{"%[0]d", []any{1}},
+	{"%[3]d", []any{1, 2}},
+	{"%[-1]d", []any{1}},
+	{"%[x]d", []any{1}},
+	{"%[2]d", nil},
+	{"%[1]", []any{1}},
+	{"%[1", []any{1}},
+	{"%[]d", []any{1}},
+	{"%[99999999999999999999]d", []any{1}},
+	{"%[2]*d", []any{1}},
+
+	// percent signs and malformed verbs
+	{"100%%", nil},
+	{"%5%|%-5%|%.2%|", nil},
+	{"%", nil},
+	{"trailing %", []any{1}},
+	{"%!", nil},
+	{"%!d", []any{1}},
+	{"%z", []any{1}},
+	{"%d", []any{"str"}},
+	{"%s", []any{nil}},
+	{"%d", []any{nil}},
+	{"%-", nil},
+	{"%5", nil},
+	{"%.", nil},
+	{"%1.2", nil},
+	{"%+", nil},
+
+	// missing and extra arguments
+	{"%d %d", []any{1}},
+	{"%s", nil},
+	{"no verbs", []any{1, "a", nil}},
+	{"%d", []any{1, 2, "three"}},
+	{"%[2]d", []any{1, 2, 3}},
+	{"%*d", nil},
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Formats without color verbs are substituted exactly as fmt.Sprintf does
+func TestSprintfMatchesFmt(t *testing.T) {
+
+	for _, test := range fmtCases {
+		want := fmt.Sprintf(test.format, test.args...)
+		if got := Sprintf(test.format, test.args...); got != want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", test.format, test.args, got, want)
+		}
+	}
+}
+
+// Precompiled formats without color verbs are substituted exactly as
+// fmt.Sprintf does, if they can be compiled at all
+func TestFormatSprintfMatchesFmt(t *testing.T) {
+
+	for _, test := range fmtCases {
+		f, err := Compile(test.format)
+		if err != nil {
+			continue
+		}
+		want := fmt.Sprintf(test.format, test.args...)
+		if got := f.Sprintf(test.args...); got != want {
+			t.Errorf("Compile(%q).Sprintf(%v) = %q, want %q", test.format, test.args, got, want)
+		}
+	}
+}
+
+// Errors created by Errorf have the same message as those created by
+// fmt.Errorf, and wrap the same errors
+func TestErrorfMatchesFmt(t *testing.T) {
+
+	errA, errB := errors.New("a"), errors.New("b")
+	for _, test := range []fmtCase{
+		{"plain %d", []any{1}},
+		{"wrapped: %w", []any{errA}},
+		{"wrapped: %w and %w", []any{errA, errB}},
+		{"not an error: %w", []any{1}},
+		{"%[2]w %[1]w", []any{errA, errB}},
+	} {
+		want := fmt.Errorf(test.format, test.args...)
+		got := Errorf(test.format, test.args...)
+		if got.Error() != want.Error() {
+			t.Errorf("Errorf(%q, %v) = %q, want %q", test.format, test.args, got, want)
+		}
+		for _, err := range []error{errA, errB} {
+			if errors.Is(got, err) != errors.Is(want, err) {
+				t.Errorf("errors.Is(Errorf(%q, %v), %v) = %v, want %v", test.format, test.args, err, errors.Is(got, err), errors.Is(want, err))
+			}
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
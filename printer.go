@@ -0,0 +1,660 @@
+// -*- coding: utf-8 -*-
+// printer.go
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 23:41:52.118604723 (1792194112)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following markers are inserted in the output, as fmt does, when a color
+// verb is never closed, when its argument index is not valid, when there is no
+// argument for it, when it is not a color specification, or when its textual
+// specification (either inline or given as a string) is not correctly
+// written. The last ones are used as format strings for inserting the type of
+// the argument and the specification, and thus the percent sign is escaped
+const (
+	malformed_marker = "%!C(NOCLOSE)"
+	bad_index_marker = "%!C(BADINDEX)"
+	missing_marker   = "%!C(MISSING)"
+	badtype_marker   = "%%!C(BADTYPE=%T)"
+	badspec_marker   = "%%!C(BADSPEC=%s)"
+)
+
+// The following markers are those used by fmt to report problems with the
+// verbs of the Printf family
+const (
+	bad_width_marker = "%!(BADWIDTH)"
+	bad_prec_marker  = "%!(BADPREC)"
+	no_verb_marker   = "%!(NOVERB)"
+	extra_marker     = "%!(EXTRA "
+)
+
+// Printers whose buffers have grown beyond this capacity are not returned to
+// the pool
+const max_buffer_size = 64 << 10
+
+// Verb used instead of those that can not be given to fmt because they would be
+// taken as a part of the flags, width or precision, along with the beginning
+// of the marker issued by fmt for it
+const (
+	placeholder_verb   = '\ufdd0'
+	placeholder_marker = "%!\ufdd0("
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A printer keeps the state of the substitution of the verbs of a format
+// string. The arguments are not stored in the printer but given to its methods
+// so that they do not escape to the heap. Like fmt, the argument to use next is
+// given by argNum, and reordered becomes true as soon as an explicit argument
+// index is found. When wrapErrs is true, the verb %w is accepted and the
+// indexes of its arguments are stored in wrappedErrs. The stack contains the
+// renditions shown within all the color verbs enclosing the text being
+// processed, from the outermost to the innermost, and cur is the rendition
+// shown by the terminal at the end of the buffer, which is changed only right
+// before showing text. precs contains the precisions of the color verbs being
+// processed, if any, and visible is the number of visible runes in the buffer
+// up to the position counted. err is the first error found. All renditions are shown
+// with the color profile given in profile, and styles and colors of underlines
+// are shown only if underlines is true. Printers are kept in a pool to reuse
+// their buffers
+type printer struct {
+	buf         []byte
+	argNum      int
+	reordered   bool
+	wrapErrs    bool
+	wrappedErrs []int
+	stack       []rendition
+	cur         rendition
+	precs       []precision
+	visible     int
+	counted     int
+	profile     ColorProfile
+	underlines  bool
+	err         error
+}
+
+// The layout of the contents of a color verb, i.e., their width and precision,
+// both measured in visible runes, and whether they have to be padded to the
+// right (minus) or to the left
+type layout struct {
+	width        int
+	widthPresent bool
+	prec         int
+	precPresent  bool
+	minus        bool
+}
+
+// The precision of a color verb being processed, i.e., the number of visible
+// runes shown at most after the given number of visible runes in the buffer
+type precision struct {
+	start int
+	prec  int
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Pool of printers
+var printerPool = sync.Pool{
+	New: func() any { return new(printer) },
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return a printer from the pool ready to substitute verbs, which shows
+// renditions as when no writer is used at all
+func newPrinter() *printer {
+
+	p := printerPool.Get().(*printer)
+	p.setWriter(nil)
+	return p
+}
+
+// Return the verb to be given to fmt for the given one. Verbs which can not be
+// written after the flags, width and precision because they would be taken as
+// a part of them (e.g., digits) are not valid for any argument. Thus, a
+// placeholder which is not valid either is used instead
+func fmtVerb(verb rune) rune {
+
+	if verb == '*' || verb == '.' || verb == '[' || ('0' <= verb && verb <= '9') || strings.ContainsRune(verb_flags, verb) {
+		return placeholder_verb
+	}
+	return verb
+}
+
+// Return the integer stored in the argNum-th argument, whether it was an
+// integer at all, and the index of the next argument to use, exactly as fmt
+// does for computing widths and precisions given with '*'
+func intFromArg(a []any, argNum int) (num int, isInt bool, newArgNum int) {
+
+	newArgNum = argNum
+	if argNum < len(a) {
+
+		// Almost always the argument is an int
+		num, isInt = a[argNum].(int)
+		if !isInt {
+
+			// Otherwise, check whether it is any other integer type whose
+			// value fits in an int
+			switch v := reflect.ValueOf(a[argNum]); v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				n := v.Int()
+				if int64(int(n)) == n {
+					num = int(n)
+					isInt = true
+				}
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				n := v.Uint()
+				if int64(n) >= 0 && uint64(int(n)) == n {
+					num = int(n)
+					isInt = true
+				}
+			}
+		}
+		newArgNum = argNum + 1
+		if tooLarge(num) {
+			num = 0
+			isInt = false
+		}
+	}
+
+	return
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Show renditions as supported by the given writer, or as when no writer is
+// used at all if it is nil
+func (p *printer) setWriter(w io.Writer) {
+	p.profile = writerColorProfile(w)
+	p.underlines = writerUnderlines(w)
+}
+
+// Return this printer to the pool. As fmt does, printers with large buffers are
+// not kept to avoid retaining too much memory
+func (p *printer) free() {
+
+	if cap(p.buf) > max_buffer_size {
+		return
+	}
+	p.buf = p.buf[:0]
+	p.argNum = 0
+	p.reordered = false
+	p.wrapErrs = false
+	p.wrappedErrs = p.wrappedErrs[:0]
+	p.stack = p.stack[:0]
+	p.cur = rendition{}
+	p.precs = p.precs[:0]
+	p.visible = 0
+	p.counted = 0
+	p.err = nil
+	printerPool.Put(p)
+}
+
+// Record the given error unless another one was found before
+func (p *printer) setError(err error) {
+
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Return the argument to use given an explicit argument index. It returns the
+// new argument number and false if the index is not valid
+func (p *printer) argNumber(index argIndex, a []any) (argNum int, ok bool) {
+
+	if !index.present {
+		return p.argNum, true
+	}
+	p.reordered = true
+	if index.ok && 1 <= index.n && index.n <= len(a) {
+		return index.n - 1, true
+	}
+
+	return p.argNum, false
+}
+
+// Compute the flags, width and precision of the given verb, consuming the
+// arguments given with '*', and move to the argument of the verb. It mimics the
+// way fmt processes verbs, and it returns false if any explicit argument index
+// is not valid
+func (p *printer) argWidthPrec(spec *verbSpec, a []any) (flags string, width int, widthPresent bool, prec int, precPresent bool, goodArgNum bool) {
+
+	goodArgNum = !spec.badArgNumber
+	flags = spec.flags
+
+	// Do we have an explicit argument index?
+	var ok bool
+	if p.argNum, ok = p.argNumber(spec.widthIndex, a); !ok {
+		goodArgNum = false
+	}
+
+	// Do we have width?
+	width, widthPresent = spec.width, spec.widthPresent
+	if spec.widthStar {
+		width, widthPresent, p.argNum = intFromArg(a, p.argNum)
+		if !widthPresent {
+			p.buf = append(p.buf, bad_width_marker...)
+		}
+
+		// A negative width is taken as the '-' flag
+		if width < 0 {
+			width = -width
+			flags += "-"
+		}
+	}
+
+	// Do we have precision?
+	prec, precPresent = spec.prec, spec.precPresent
+	if spec.dot {
+		if p.argNum, ok = p.argNumber(spec.precIndex, a); !ok {
+			goodArgNum = false
+		}
+		if spec.precStar {
+			prec, precPresent, p.argNum = intFromArg(a, p.argNum)
+
+			// A negative precision is taken as no precision at all
+			if prec < 0 {
+				prec = 0
+				precPresent = false
+			}
+			if !precPresent {
+				p.buf = append(p.buf, bad_prec_marker...)
+			}
+		}
+	}
+
+	// An explicit argument index might also be given right before the verb
+	if p.argNum, ok = p.argNumber(spec.verbIndex, a); !ok {
+		goodArgNum = false
+	}
+
+	return
+}
+
+// Substitute the given verb of the Printf family with the next argument(s).
+// It mimics the way fmt processes verbs so that the arguments consumed and the
+// output generated are exactly the same
+func (p *printer) printVerb(n *node, a []any) {
+
+	spec := &n.spec
+	flags, width, widthPresent, prec, precPresent, goodArgNum := p.argWidthPrec(spec, a)
+
+	// Finally, process the verb
+	switch {
+	case spec.verb == no_verb:
+		p.setError(&FormatError{Offset: n.offset, Err: ErrMalformedVerb})
+		p.buf = append(p.buf, no_verb_marker...)
+	case spec.verb == '%':
+
+		// Percent does not absorb operands and ignores width and precision
+		p.buf = append(p.buf, '%')
+	case !goodArgNum:
+		p.setError(&FormatError{Offset: n.offset, Err: ErrMalformedVerb})
+		p.buf = append(p.buf, "%!"...)
+		p.buf = utf8.AppendRune(p.buf, spec.verb)
+		p.buf = append(p.buf, "(BADINDEX)"...)
+	case p.argNum >= len(a):
+		p.setError(&FormatError{Offset: n.offset, Err: ErrMissingArgument})
+		p.buf = append(p.buf, "%!"...)
+		p.buf = utf8.AppendRune(p.buf, spec.verb)
+		p.buf = append(p.buf, "(MISSING)"...)
+	default:
+
+		// Let fmt substitute the verb with the argument, once the width and
+		// precision are known. Only in case they are given as arguments,
+		// the format passed to fmt has to be computed again
+		verb, format := spec.verb, spec.format
+		if verb == 'w' && p.wrapErrs {
+			p.wrappedErrs = append(p.wrappedErrs, p.argNum)
+			if _, isErr := a[p.argNum].(error); isErr {
+				verb = 'v'
+			}
+		}
+		if spec.widthStar || spec.precStar || verb != spec.verb {
+			format = simpleVerb(flags, width, widthPresent, prec, precPresent, fmtVerb(verb))
+		}
+		p.printArg(a[p.argNum], format, verb)
+		p.argNum++
+	}
+}
+
+// Let fmt substitute the given argument using the given format, which contains
+// a single verb. If the verb had to be substituted by a placeholder (see
+// fmtVerb) then it is substituted back in the markers issued by fmt
+func (p *printer) printArg(arg any, format string, verb rune) {
+
+	if fmtVerb(verb) == verb {
+		p.buf = fmt.Appendf(p.buf, format, arg)
+		return
+	}
+
+	start := len(p.buf)
+	p.buf = fmt.Appendf(p.buf, format, arg)
+	marker := strings.ReplaceAll(string(p.buf[start:]), placeholder_marker, "%!"+string(verb)+"(")
+	p.buf = append(p.buf[:start], marker...)
+}
+
+// Substitute the given color verb with the next argument, and all the verbs
+// enclosed in it
+func (p *printer) printColorVerb(n *node, a []any) {
+
+	// Compute the width and precision of the color verb, and move to its
+	// argument
+	flags, width, widthPresent, prec, precPresent, ok := p.argWidthPrec(&n.spec, a)
+	l := layout{width: width, widthPresent: widthPresent, prec: prec, precPresent: precPresent, minus: strings.Contains(flags, "-")}
+
+	// Get the rendition of this color verb. If it can not be computed, then a
+	// marker is issued instead and the contents of the color verb are shown
+	// with the effects of the enclosing verbs
+	if !ok {
+		p.setError(&FormatError{Offset: n.offset, Err: ErrMalformedVerb})
+		p.sync()
+		p.buf = append(p.buf, bad_index_marker...)
+		p.printEffect(rendition{}, l, n.children, a)
+		return
+	}
+	if p.argNum >= len(a) {
+		p.setError(&FormatError{Offset: n.offset, Err: ErrMissingArgument})
+		p.sync()
+		p.buf = append(p.buf, missing_marker...)
+		p.printEffect(rendition{}, l, n.children, a)
+		return
+	}
+	arg := a[p.argNum]
+	p.argNum++
+	r, err := effectRendition(arg)
+	if err != nil {
+		p.setError(&FormatError{Offset: n.offset, Err: err, Arg: arg})
+		p.sync()
+		if spec, ok := arg.(string); ok {
+			p.buf = fmt.Appendf(p.buf, badspec_marker, spec)
+		} else {
+			p.buf = fmt.Appendf(p.buf, badtype_marker, arg)
+		}
+		p.printEffect(rendition{}, l, n.children, a)
+		return
+	}
+
+	p.printEffect(r, l, n.children, a)
+}
+
+// Substitute the given color verb with an inline specification, and all the
+// verbs enclosed in it
+func (p *printer) printInlineColorVerb(n *node, a []any) {
+
+	// The width and precision of inline color verbs are never given as
+	// arguments
+	spec := &n.spec
+	l := layout{width: spec.width, widthPresent: spec.widthPresent, prec: spec.prec, precPresent: spec.precPresent, minus: strings.Contains(spec.flags, "-")}
+
+	// If the specification is not correctly written, a marker is issued
+	// instead and the contents are shown with the effects of the enclosing
+	// verbs. The specification starts right after the parenthesis
+	if n.err != nil {
+		p.setError(&FormatError{Offset: n.offset, Err: n.err})
+		p.sync()
+		open := strings.IndexByte(n.text, '(') + 1
+		p.buf = fmt.Appendf(p.buf, badspec_marker, n.text[open:closingParen(n.text, open)])
+		p.printEffect(rendition{}, l, n.children, a)
+		return
+	}
+
+	p.printEffect(n.effect, l, n.children, a)
+}
+
+// Return the rendition shown within all the color verbs enclosing the text
+// being processed
+func (p *printer) top() rendition {
+
+	if len(p.stack) == 0 {
+		return rendition{}
+	}
+	return p.stack[len(p.stack)-1]
+}
+
+// Return the number of visible runes in the buffer. Only the runes added
+// since the last time are counted, so that the buffer is scanned only once
+func (p *printer) count() int {
+
+	p.visible += visibleWidth(p.buf[p.counted:])
+	p.counted = len(p.buf)
+	return p.visible
+}
+
+// Return true if the precision of any of the color verbs being processed has
+// been reached, so that no more text is shown
+func (p *printer) truncated() bool {
+
+	if len(p.precs) == 0 {
+		return false
+	}
+	n := p.count()
+	for _, prec := range p.precs {
+		if n-prec.start >= prec.prec {
+			return true
+		}
+	}
+	return false
+}
+
+// Issue the transition from the rendition shown by the terminal to the one
+// that has to be shown for the text that follows, if they are different. It
+// must be called right before showing any text, and also at the end, when the
+// stack is empty, so that all colors and properties are reset. Nothing is
+// issued if the text that follows is truncated anyway
+func (p *printer) sync() {
+
+	if p.truncated() {
+		return
+	}
+	if want := p.top(); p.cur != want {
+		p.buf = appendTransition(p.buf, p.cur, want)
+		p.cur = want
+	}
+}
+
+// Show the given nodes with the given rendition and layout. The rendition is
+// shown within the renditions of the enclosing color verbs, and it is issued
+// only when text is shown, along with the codes that change just the
+// attributes which are different from those shown before. Empty renditions
+// are not issued at all. The contents are truncated to the precision and
+// padded with blanks to the width, both measured in visible runes, and no
+// rendition is issued once the precision is reached. The padding is shown with
+// the effects of the enclosing color verbs. The rendition is first quantized to
+// the color profile of the printer, so that it is empty with Ascii
+func (p *printer) printEffect(r rendition, l layout, children []node, a []any) {
+
+	// If the contents are padded to the left, the padding is inserted at the
+	// start with the effects of the enclosing color verbs
+	r = r.quantize(p.profile, p.underlines)
+	if l.widthPresent && !l.minus {
+		p.sync()
+	}
+	start, visible := len(p.buf), 0
+	if l.widthPresent || l.precPresent {
+		visible = p.count()
+	}
+	if !r.neutral() {
+		p.stack = append(p.stack, r.within(p.top()))
+	}
+	if l.precPresent {
+		p.precs = append(p.precs, precision{start: visible, prec: l.prec})
+	}
+	p.printNodes(children, a)
+
+	// Once the contents are truncated, the visible runes have to be counted
+	// again, but the number of those kept is already known
+	if l.precPresent {
+		p.precs = p.precs[:len(p.precs)-1]
+		var n int
+		p.buf, n = truncateVisible(p.buf, start, l.prec)
+		p.visible, p.counted = visible+n, len(p.buf)
+	}
+	if !r.neutral() {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+
+	// Pad the contents either to the right or to the left
+	if !l.widthPresent {
+		return
+	}
+	if n := p.count() - visible; n < l.width {
+		pad := l.width - n
+		if l.minus {
+			p.sync()
+		}
+		end := len(p.buf)
+		p.buf = appendPadding(p.buf, pad)
+		if !l.minus {
+			copy(p.buf[start+pad:], p.buf[start:end])
+			for i := start; i < start+pad; i++ {
+				p.buf[i] = ' '
+			}
+		}
+		p.visible, p.counted = visible+l.width, len(p.buf)
+	}
+}
+
+// Issue the marker of a color verb which is never closed. As fmt does with bad
+// verbs, its arguments are consumed anyway, i.e., its effect and its width and
+// precision, if they are given with '*', so that the verbs that follow are
+// substituted with the same arguments as if it were closed
+func (p *printer) printMalformed(n *node, a []any) {
+
+	p.setError(&FormatError{Offset: n.offset, Err: ErrMalformedVerb})
+	if n.effectArg() {
+		p.argWidthPrec(&n.spec, a)
+		if p.argNum < len(a) {
+			p.argNum++
+		}
+	}
+	p.buf = append(p.buf, malformed_marker...)
+}
+
+// Substitute all the verbs in the given nodes
+func (p *printer) printNodes(nodes []node, a []any) {
+
+	for idx := range nodes {
+
+		switch nodes[idx].kind {
+		case textNode:
+			p.sync()
+			p.buf = append(p.buf, nodes[idx].text...)
+		case verbNode:
+			p.sync()
+			p.printVerb(&nodes[idx], a)
+		case colorNode:
+			p.printColorVerb(&nodes[idx], a)
+		case inlineNode:
+			p.printInlineColorVerb(&nodes[idx], a)
+		case malformedNode:
+			p.sync()
+			p.printMalformed(&nodes[idx], a)
+		}
+	}
+}
+
+// Substitute all the verbs in the nodes of a format string, either color verbs
+// or those of the Printf family, and add a marker with all the arguments that
+// have not been used, if any, as fmt does. All colors and properties are reset
+// before the marker
+func (p *printer) doPrintf(nodes []node, a []any) {
+
+	p.printNodes(nodes, a)
+	p.sync()
+	p.printExtra(a)
+}
+
+// Add a marker with all the arguments that have not been used, if any, as fmt
+// does
+func (p *printer) printExtra(a []any) {
+
+	if !p.reordered && p.argNum < len(a) {
+		p.buf = append(p.buf, extra_marker...)
+		for idx, arg := range a[p.argNum:] {
+			if idx > 0 {
+				p.buf = append(p.buf, ", "...)
+			}
+			if arg == nil {
+				p.buf = append(p.buf, "<nil>"...)
+			} else {
+				p.buf = fmt.Appendf(p.buf, "%T=%v", arg, arg)
+			}
+		}
+		p.buf = append(p.buf, ')')
+	}
+}
+
+// Return the error created by golor.Errorf with the contents of the buffer,
+// wrapping the arguments given to the verb %w, if any, as fmt.Errorf does
+func (p *printer) wrapError(a []any) error {
+
+	msg := string(p.buf)
+	switch len(p.wrappedErrs) {
+	case 0:
+		return errors.New(msg)
+	case 1:
+		err, _ := a[p.wrappedErrs[0]].(error)
+		return &wrapError{msg: msg, err: err}
+	default:
+		if p.reordered {
+			slices.Sort(p.wrappedErrs)
+		}
+		var errs []error
+		for idx, argNum := range p.wrappedErrs {
+			if idx > 0 && p.wrappedErrs[idx-1] == argNum {
+				continue
+			}
+			if err, ok := a[argNum].(error); ok {
+				errs = append(errs, err)
+			}
+		}
+		return &wrapErrors{msg: msg, errs: errs}
+	}
+}
+
+// Return a format string with a single verb with the given flags, width and
+// precision
+func simpleVerb(flags string, width int, widthPresent bool, prec int, precPresent bool, verb rune) string {
+
+	output := make([]byte, 0, 16)
+	output = append(output, '%')
+	output = append(output, flags...)
+	if widthPresent {
+		output = strconv.AppendInt(output, int64(width), 10)
+	}
+	if precPresent {
+		output = append(output, '.')
+		output = strconv.AppendInt(output, int64(prec), 10)
+	}
+	output = utf8.AppendRune(output, verb)
+
+	return string(output)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,195 @@
+// -*- coding: utf-8 -*-
+// printer_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 10:31:08.550163294 (1792233068)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"io"
+	"testing"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A format string along with its arguments, which are boxed only once so that
+// benchmarks measure only the allocations done by golor
+type benchCase struct {
+	name   string
+	format string
+	args   []any
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Formats used in the benchmarks: without color verbs, with nested color verbs
+// and with effects
+var benchCases = []benchCase{
+	{
+		name:   "plain",
+		format: "%s has %d items costing %.2f",
+		args:   []any{"cart", 3, 12.5},
+	},
+	{
+		name:   "nested",
+		format: "%C{outer %C{inner %d} outer}",
+		args:   []any{Effect64(BOLD64 | 0xff0000), Effect64(ITALIC64 | 0x00ff00), 42},
+	},
+	{
+		name:   "effect",
+		format: "%C{%s} %C{%s}",
+		args: []any{
+			Effect{Fg: Color{R: 0xff, G: 0x88}, Bg: Color{B: 0x40}, Properties: BOLD | UNDERLINE}, "warning",
+			FgEffect{R: 0x20, G: 0xc0, B: 0x20}, "ok",
+		},
+	},
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// No escape sequences are issued once the precision of a color verb has been
+// reached, and the rendition shown next is computed from the one shown by the
+// terminal at the last visible rune
+func TestPrecisionTruncation(t *testing.T) {
+
+	for _, test := range []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"%5.1C{%C{ab}c}", []any{Ansi16(1), Ansi16(2)}, "    \x1b[32ma\x1b[0m"},
+		{"%C{x%.1C{%C{ab}c}y}", []any{Ansi16(3), Ansi16(1), Ansi16(2)}, "\x1b[33mx\x1b[32ma\x1b[33my\x1b[0m"},
+		{"%C{x%.2C{a%C{bc}d}y}", []any{Ansi16(3), Ansi16(1), Ansi16(2)}, "\x1b[33mx\x1b[31ma\x1b[32mb\x1b[33my\x1b[0m"},
+		{"%.0C{%s}", []any{Ansi16(1), "hidden"}, ""},
+	} {
+		if got := Sprintf(test.format, test.args...); got != test.want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", test.format, test.args, got, test.want)
+		}
+	}
+}
+
+// Color verbs which are never closed consume their arguments anyway, so that
+// the verbs that follow are substituted with the same arguments
+func TestUnclosedColorVerb(t *testing.T) {
+
+	for _, test := range []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"%C{unclosed %d", []any{Ansi16(1), 1}, "%!C(NOCLOSE)unclosed 1"},
+		{"%*.*C{unclosed %d", []any{3, 2, Ansi16(1), 1}, "%!C(NOCLOSE)unclosed 1"},
+		{"%[2]C{unclosed %d", []any{1, Ansi16(1)}, "%!C(NOCLOSE)unclosed %!d(MISSING)"},
+		{"%C(red){unclosed %d", []any{1}, "%!C(NOCLOSE)red){unclosed 1"},
+		{"%C{unclosed %d", nil, "%!C(NOCLOSE)unclosed %!d(MISSING)"},
+	} {
+		if got := Sprintf(test.format, test.args...); got != test.want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", test.format, test.args, got, test.want)
+		}
+	}
+}
+
+// Properties prefixed with "no" are unset also if they are given in the
+// enclosing color verbs, and they are set again once the nested verb ends
+func TestNegatedProperties(t *testing.T) {
+
+	for _, test := range []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"%C{%C{x}}", []any{"bold italic", "noitalic"}, "\x1b[1mx\x1b[0m"},
+		{"%C{a%C{x}b}", []any{"bold italic", "noitalic"}, "\x1b[1;3ma\x1b[23mx\x1b[3mb\x1b[0m"},
+		{"%C{a%C{x}b}", []any{"curly red", "noul"}, "\x1b[38;2;255;0;0;4:3ma\x1b[24mx\x1b[4:3mb\x1b[0m"},
+		{"%C{a%C{x}b}", []any{"bold", "nobold bold"}, "\x1b[1maxb\x1b[0m"},
+		{"%C{x}", []any{"noitalic"}, "x"},
+	} {
+		if got := Sprintf(test.format, test.args...); got != test.want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", test.format, test.args, got, test.want)
+		}
+	}
+}
+
+// Styles returned by ParseEffect leave unchanged the colors which are not
+// given, unless they are set with the methods of the style
+func TestParseEffectStyles(t *testing.T) {
+
+	bold, err := ParseEffect("bold")
+	if err != nil {
+		t.Fatalf("ParseEffect(\"bold\") = %v", err)
+	}
+	noitalic, err := ParseEffect("noitalic")
+	if err != nil {
+		t.Fatalf("ParseEffect(\"noitalic\") = %v", err)
+	}
+	for _, test := range []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"%C{x}", []any{bold.Fg(Color{R: 0xff})}, "\x1b[38;2;255;0;0;1mx\x1b[0m"},
+		{"%C{a%C{x}b}", []any{"red", bold}, "\x1b[38;2;255;0;0ma\x1b[1mx\x1b[22mb\x1b[0m"},
+		{"%C{a%C{x}b}", []any{"bold italic", noitalic}, "\x1b[1;3ma\x1b[23mx\x1b[3mb\x1b[0m"},
+	} {
+		if got := Sprintf(test.format, test.args...); got != test.want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", test.format, test.args, got, test.want)
+		}
+	}
+}
+
+// Effects of the ANSI palettes leave the background color unchanged unless it
+// is given
+func TestAnsiEffects(t *testing.T) {
+
+	blue, navy := AnsiBlue, Ansi256(17)
+	for _, test := range []struct {
+		effect any
+		want   string
+	}{
+		{EffectAnsi16{Fg: AnsiRed}, "\x1b[31mx\x1b[0m"},
+		{EffectAnsi16{Fg: AnsiRed, Bg: &blue}, "\x1b[31;44mx\x1b[0m"},
+		{EffectAnsi256{Fg: 208}, "\x1b[38;5;208mx\x1b[0m"},
+		{EffectAnsi256{Fg: 208, Bg: &navy}, "\x1b[38;5;208;48;5;17mx\x1b[0m"},
+	} {
+		if got := Sprintf("%C{x}", test.effect); got != test.want {
+			t.Errorf("Sprintf(\"%%C{x}\", %+v) = %q, want %q", test.effect, got, test.want)
+		}
+	}
+}
+
+// Sprintf allocates only the string it returns
+func BenchmarkSprintf(b *testing.B) {
+
+	for _, bench := range benchCases {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Sprintf(bench.format, bench.args...)
+			}
+		})
+	}
+}
+
+// Fprintf does not allocate at all
+func BenchmarkFprintf(b *testing.B) {
+
+	for _, bench := range benchCases {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Fprintf(io.Discard, bench.format, bench.args...)
+			}
+		})
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,224 @@
+// -*- coding: utf-8 -*-
+// profile.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 05:48:19.337102984 (1792216099)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/clinaresl/golor/utils"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following constants are the color profiles supported by golor, from the
+// richest to the poorest one
+const (
+	TrueColor ColorProfile = iota // 24-bit colors
+	ANSI256                       // colors of the extended ANSI palette
+	ANSI16                        // colors of the basic ANSI palette
+	Ascii                         // no colors nor properties at all
+)
+
+// Maximum number of colors whose quantization is kept in the cache of colors
+const max_cached_colors = 4096
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A ColorProfile describes the colors supported by a terminal. Colors which are
+// not supported are substituted by the perceptually closest color of the
+// palette supported by the profile when they are shown, and no escape
+// sequences are issued at all with the profile Ascii
+type ColorProfile int
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// The color profile set with SetColorProfile plus one, so that zero means that
+// no color profile has been set
+var colorProfile atomic.Int32
+
+// The coordinates in the Oklab color space of the colors of both ANSI palettes,
+// which are computed only once and only if needed
+var (
+	ansi16Oklab = sync.OnceValue(func() [][3]float64 {
+		return paletteOklab(16, func(idx int) Color { return Ansi16(idx).Color() })
+	})
+	ansi256Oklab = sync.OnceValue(func() [][3]float64 {
+		return paletteOklab(256, func(idx int) Color { return Ansi256(idx).Color() })
+	})
+)
+
+// Colors are quantized every time they are shown, so that the result is kept
+// in a cache for every palette. Because many different colors might be used,
+// e.g., in gradients, the caches are bounded
+var (
+	quantizeMutex sync.RWMutex
+	quantizeCache = map[ColorProfile]map[Color]uint8{
+		ANSI256: make(map[Color]uint8),
+		ANSI16:  make(map[Color]uint8),
+	}
+)
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the coordinates in the Oklab color space of the first n colors of a
+// palette, which are returned by the given function
+func paletteOklab(n int, color func(int) Color) [][3]float64 {
+
+	coords := make([][3]float64, n)
+	for idx := range n {
+		c := color(idx)
+		coords[idx][0], coords[idx][1], coords[idx][2] = utils.RgbToOklab(c.R, c.G, c.B)
+	}
+	return coords
+}
+
+// Return the index of the color of the given palette, from the first one given,
+// which is perceptually closest to the given color, i.e., whose euclidean
+// distance in the Oklab color space is the smallest
+func nearestColor(c Color, palette [][3]float64, first int) uint8 {
+
+	l, a, b := utils.RgbToOklab(c.R, c.G, c.B)
+	best, bestDist := first, math.Inf(1)
+	for idx := first; idx < len(palette); idx++ {
+		dl, da, db := palette[idx][0]-l, palette[idx][1]-a, palette[idx][2]-b
+		if dist := dl*dl + da*da + db*db; dist < bestDist {
+			best, bestDist = idx, dist
+		}
+	}
+
+	return uint8(best)
+}
+
+// Return the index of the color of the palette of the given profile, either
+// ANSI256 or ANSI16, which is perceptually closest to the given color. With
+// ANSI256, the first 16 colors are never used because their appearance depends
+// on the terminal
+func quantizeColor(c Color, profile ColorProfile) uint8 {
+
+	quantizeMutex.RLock()
+	idx, ok := quantizeCache[profile][c]
+	quantizeMutex.RUnlock()
+	if ok {
+		return idx
+	}
+
+	if profile == ANSI256 {
+		idx = nearestColor(c, ansi256Oklab(), 16)
+	} else {
+		idx = nearestColor(c, ansi16Oklab(), 0)
+	}
+	quantizeMutex.Lock()
+	if len(quantizeCache[profile]) < max_cached_colors {
+		quantizeCache[profile][c] = idx
+	}
+	quantizeMutex.Unlock()
+
+	return idx
+}
+
+// Return the color profile set with SetColorProfile, and whether any has been
+// set at all
+func explicitColorProfile() (ColorProfile, bool) {
+
+	if profile := colorProfile.Load(); profile > 0 {
+		return ColorProfile(profile - 1), true
+	}
+	return TrueColor, false
+}
+
+// SetColorProfile sets the color profile used for showing colors everywhere,
+// overriding the profiles detected for files such as the standard output (see
+// DetectColorProfile). No colors are shown anyway with ColorNever (see
+// ColorMode)
+func SetColorProfile(profile ColorProfile) {
+	colorProfile.Store(int32(profile) + 1)
+}
+
+// CurrentColorProfile returns the color profile used for showing colors when
+// they are not written to a file, e.g., by golor.Sprintf or [Style.Render]. It
+// is Ascii if colors are disabled (see [ColorMode]), and otherwise the profile
+// set with SetColorProfile, or TrueColor if none has been set
+func CurrentColorProfile() ColorProfile {
+	return writerColorProfile(nil)
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the name of the color profile
+func (profile ColorProfile) String() string {
+
+	switch profile {
+	case TrueColor:
+		return "truecolor"
+	case ANSI256:
+		return "ansi256"
+	case ANSI16:
+		return "ansi16"
+	case Ascii:
+		return "ascii"
+	}
+	return "unknown"
+}
+
+// Return this color as shown with the given color profile
+func (c termColor) quantize(profile ColorProfile) termColor {
+
+	switch {
+	case c.kind == noColor || profile == TrueColor:
+		return c
+	case profile == Ascii:
+		return termColor{}
+	case c.kind == ansi16Color || c.kind == defaultColor:
+		return c
+	case c.kind == ansi256Color && profile == ANSI256:
+		return c
+	case c.kind == ansi256Color && c.index < 16:
+		return ansi16TermColor(Ansi16(c.index))
+	case profile == ANSI256:
+		return ansi256TermColor(Ansi256(quantizeColor(c.rgb, profile)))
+	}
+
+	return ansi16TermColor(Ansi16(quantizeColor(c.color(), profile)))
+}
+
+// Return this rendition as shown with the given color profile, and by a
+// terminal which supports styles and colors of underlines only if underlines
+// is true. Colors are substituted by the closest ones supported by the
+// profile, and the rendition is empty with the profile Ascii
+func (r rendition) quantize(profile ColorProfile, underlines bool) rendition {
+
+	if profile == Ascii {
+		return rendition{}
+	}
+	r.fg = r.fg.quantize(profile)
+	r.bg = r.bg.quantize(profile)
+	r.ulColor = r.ulColor.quantize(profile)
+
+	// Styles of underlines are shown otherwise as plain underlines
+	if !underlines {
+		if r.ulStyle != UnderlineNone {
+			r.properties |= UNDERLINE
+		}
+		r.ulStyle, r.ulColor = UnderlineNone, termColor{}
+	}
+
+	return r
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,481 @@
+// -*- coding: utf-8 -*-
+// rendition.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 01:02:48.337190452 (1792198968)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following constants distinguish the different kinds of colors that can
+// be used in a rendition
+const (
+	noColor      = iota // the color is not set
+	rgbColor            // a 24-bit color
+	ansi16Color         // a color of the basic ANSI palette
+	ansi256Color        // a color of the extended ANSI palette
+	defaultColor        // the color used by default by the terminal
+)
+
+// The following constants are used for unsetting underlines, either plain or
+// with a style
+const (
+	underline_reset      = "24"
+	underline_properties = UNDERLINE | DOUBLE_UNDERLINE
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A color used in a rendition, which might be either a 24-bit color, given in
+// rgb, or a color of any of the ANSI palettes, given with its index
+type termColor struct {
+	kind  int
+	rgb   Color
+	index uint8
+}
+
+// A rendition is the internal representation of any color specification: the
+// foreground and background colors, the properties and the style and color of
+// underlines. Properties in cleared are unset when the rendition is nested in
+// another one. Renditions are written with ANSI escape sequences (SGR, Select
+// Graphic Rendition) directly in byte slices
+type rendition struct {
+	fg, bg     termColor
+	properties uint16
+	ulStyle    UnderlineStyle
+	ulColor    termColor
+	cleared    uint16
+}
+
+// Properties which are unset with the same code, e.g., 22 for BOLD and DIM
+type propertyReset struct {
+	mask uint16
+	code string
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Codes used for unsetting properties, but underlines
+var propertyResets = []propertyReset{
+	{mask: BOLD | DIM, code: "22"},
+	{mask: ITALIC, code: "23"},
+	{mask: SLOW_BLINK | RAPID_BLINK, code: "25"},
+	{mask: REVERSE, code: "27"},
+	{mask: CONCEAL, code: "28"},
+	{mask: CROSSED_OUT, code: "29"},
+	{mask: FRAMED | ENCIRCLED, code: "54"},
+	{mask: OVERLINE, code: "55"},
+	{mask: SUPERSCRIPT | SUBSCRIPT, code: "75"},
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the given 24-bit color to be used in a rendition, unless it is
+// Default
+func rgbTermColor(c Color) termColor {
+
+	if c.dflt {
+		return termColor{kind: defaultColor}
+	}
+	return termColor{kind: rgbColor, rgb: c}
+}
+
+// Return the given color of the basic ANSI palette to be used in a rendition
+func ansi16TermColor(c Ansi16) termColor {
+	return termColor{kind: ansi16Color, index: uint8(c) & 0xf}
+}
+
+// Return the given color of the extended ANSI palette to be used in a
+// rendition
+func ansi256TermColor(c Ansi256) termColor {
+	return termColor{kind: ansi256Color, index: uint8(c)}
+}
+
+// Append to the byte slice the ANSI codes of the given color, separated with
+// semicolons
+func appendColor(b []byte, c Color) []byte {
+
+	b = strconv.AppendUint(b, uint64(c.R), 10)
+	b = append(b, ';')
+	b = strconv.AppendUint(b, uint64(c.G), 10)
+	b = append(b, ';')
+	return strconv.AppendUint(b, uint64(c.B), 10)
+}
+
+// Append to the byte slice the ANSI codes that set the given color either as
+// the foreground or the background color
+func appendTermColor(b []byte, c termColor, background bool) []byte {
+
+	switch c.kind {
+	case rgbColor:
+		if background {
+			b = append(b, background_prefix...)
+		} else {
+			b = append(b, foreground_prefix...)
+		}
+		b = append(b, ';')
+		return appendColor(b, c.rgb)
+
+	case ansi16Color:
+		code := ansi16_foreground + int(c.index)
+		if c.index >= 8 {
+			code = ansi16_bright_foreground + int(c.index) - 8
+		}
+		if background {
+			code += ansi16_background - ansi16_foreground
+		}
+		return strconv.AppendInt(b, int64(code), 10)
+
+	case ansi256Color:
+		if background {
+			b = append(b, ansi256_background...)
+		} else {
+			b = append(b, ansi256_foreground...)
+		}
+		b = append(b, ';')
+		return strconv.AppendUint(b, uint64(c.index), 10)
+
+	case defaultColor:
+		if background {
+			return append(b, default_background...)
+		}
+		return append(b, default_foreground...)
+	}
+
+	return b
+}
+
+// Append to the byte slice the ANSI escape sequence that changes the colors and
+// properties shown by the terminal from those of the rendition from to those
+// of the rendition to. Only the attributes which change are issued, and
+// properties are unset with their specific codes, e.g., 22 or 23. However, if
+// it is shorter, all attributes are reset and those of to are issued next
+func appendTransition(b []byte, from, to rendition) []byte {
+
+	switch {
+	case from == to:
+		return b
+	case to.empty():
+		return append(b, suffix...)
+	case from.empty():
+		return to.appendSGR(b)
+	}
+
+	// Every code is followed by a semicolon, and the last one is substituted
+	// by the final byte of the sequence
+	start := len(b)
+	b = append(b, prefix...)
+	b = appendColorTransition(b, from.fg, to.fg, false)
+	b = appendColorTransition(b, from.bg, to.bg, true)
+
+	// Unset the properties which are not shown anymore. Since some codes
+	// unset several properties, those which are still shown are set again
+	// along with those which are new. Underlines are considered separately
+	props := to.properties &^ underline_properties
+	added := props &^ from.properties
+	removed := from.properties &^ to.properties
+	for _, reset := range propertyResets {
+		if removed&reset.mask != 0 {
+			b = append(b, reset.code...)
+			b = append(b, ';')
+			added |= props & reset.mask
+		}
+	}
+	var idx uint16
+	for idx = BOLD; idx <= SUBSCRIPT; idx <<= 1 {
+		if added&idx != 0 {
+			b = append(b, propertyPrefix[idx]...)
+			b = append(b, ';')
+		}
+	}
+
+	// Underlines are changed only if they are shown differently
+	if ul := to.underline(); ul != from.underline() {
+		switch {
+		case ul == UnderlineNone:
+			b = append(b, underline_reset...)
+		case to.ulStyle != UnderlineNone:
+			b = append(b, underline_style_prefix...)
+			b = strconv.AppendUint(b, uint64(to.ulStyle), 10)
+		case ul == UnderlineDouble:
+			b = append(b, propertyPrefix[DOUBLE_UNDERLINE]...)
+		default:
+			b = append(b, propertyPrefix[UNDERLINE]...)
+		}
+		b = append(b, ';')
+	}
+	if from.ulColor != to.ulColor {
+		if to.ulColor.set() {
+			b = appendUnderlineColor(b, to.ulColor)
+		} else {
+			b = append(b, default_underline_color...)
+		}
+		b = append(b, ';')
+	}
+
+	// The renditions might be shown exactly the same, e.g., if a color is
+	// unset in one of them and Default in the other
+	if len(b) == start+len(prefix) {
+		return b[:start]
+	}
+	b[len(b)-1] = 'm'
+
+	// Finally, check whether resetting all attributes is shorter. The full
+	// sequence is written after the transition and moved if it is chosen
+	diff := len(b) - start
+	b = append(b, suffix...)
+	b = to.appendSGR(b)
+	if len(b)-start-diff < diff {
+		return append(b[:start], b[start+diff:]...)
+	}
+	return b[:start+diff]
+}
+
+// Append to the byte slice the ANSI codes, followed by a semicolon, that change
+// either the foreground or the background color from the color from to the
+// color to, if they are shown differently. Colors which are not shown anymore
+// are substituted by the colors used by default by the terminal
+func appendColorTransition(b []byte, from, to termColor, background bool) []byte {
+
+	switch {
+	case from == to, from.terminalDefault() && to.terminalDefault():
+		return b
+	case to.set():
+		b = appendTermColor(b, to, background)
+	case background:
+		b = append(b, default_background...)
+	default:
+		b = append(b, default_foreground...)
+	}
+	return append(b, ';')
+}
+
+// Return the length of the ANSI escape sequence (CSI, Control Sequence
+// Introducer) which starts at the i-th position of the byte slice, or 0 if
+// there is none. These sequences start with ESC [ and end with a byte in the
+// range 0x40-0x7e
+func escapeLen(b []byte, i int) int {
+
+	if b[i] != '\033' || i+1 >= len(b) || b[i+1] != '[' {
+		return 0
+	}
+	j := i + 2
+	for j < len(b) && (b[j] < 0x40 || b[j] > 0x7e) {
+		j++
+	}
+
+	return min(j+1, len(b)) - i
+}
+
+// Return the number of runes of the given text which are shown, i.e., without
+// the escape sequences it might contain
+func visibleWidth(b []byte) (n int) {
+
+	for i := 0; i < len(b); {
+		if size := escapeLen(b, i); size > 0 {
+			i += size
+			continue
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		i += size
+		n++
+	}
+
+	return
+}
+
+// Truncate the text in the byte slice which starts at the given position so
+// that it contains at most the given number of visible runes, and return it
+// along with the number of visible runes kept. Escape sequences are kept,
+// since the printer issues none after the last visible rune, so that the
+// terminal ends up showing the same colors and properties
+func truncateVisible(b []byte, start, prec int) ([]byte, int) {
+
+	n, j := 0, start
+	for i := start; i < len(b); {
+		size := escapeLen(b, i)
+		if size == 0 {
+			_, size = utf8.DecodeRune(b[i:])
+			if n == prec {
+				i += size
+				continue
+			}
+			n++
+		}
+		j += copy(b[j:], b[i:i+size])
+		i += size
+	}
+
+	return b[:j], n
+}
+
+// Append the given number of blanks to the byte slice
+func appendPadding(b []byte, n int) []byte {
+
+	for ; n > 0; n-- {
+		b = append(b, ' ')
+	}
+	return b
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return true if this color is set at all
+func (c termColor) set() bool {
+	return c.kind != noColor
+}
+
+// Return true if this color is shown with the color used by default by the
+// terminal, either because it is not set or because it is Default
+func (c termColor) terminalDefault() bool {
+	return c.kind == noColor || c.kind == defaultColor
+}
+
+// Return the RGB color used by xterm for showing this color, or Default if it
+// is the color used by default by the terminal
+func (c termColor) color() Color {
+
+	switch c.kind {
+	case defaultColor:
+		return Default
+	case ansi16Color:
+		return Ansi16(c.index).Color()
+	case ansi256Color:
+		return Ansi256(c.index).Color()
+	}
+	return c.rgb
+}
+
+// Return the style of the underlines shown with this rendition, if any. Styles
+// are shown instead of plain underlines, and double underlines are issued
+// after single ones
+func (r *rendition) underline() UnderlineStyle {
+
+	switch {
+	case r.ulStyle != UnderlineNone:
+		return r.ulStyle
+	case r.properties&DOUBLE_UNDERLINE != 0:
+		return UnderlineDouble
+	case r.properties&UNDERLINE != 0:
+		return UnderlineSingle
+	}
+	return UnderlineNone
+}
+
+// Return the rendition shown when this rendition is nested in the given one,
+// i.e., the colors of this rendition, if they are set, or those of the given
+// one otherwise, and the properties of both, but those cleared in this
+// rendition. Underlines of this rendition, if any, are shown instead of those
+// of the given one, and clearing UNDERLINE clears underlines of any style
+func (r rendition) within(parent rendition) rendition {
+
+	cleared := r.cleared
+	if cleared&UNDERLINE != 0 {
+		cleared |= underline_properties
+	}
+	r.cleared = 0
+
+	if !r.fg.set() {
+		r.fg = parent.fg
+	}
+	if !r.bg.set() {
+		r.bg = parent.bg
+	}
+	if !r.ulColor.set() {
+		r.ulColor = parent.ulColor
+	}
+	if r.underline() == UnderlineNone {
+		if cleared&UNDERLINE == 0 {
+			r.ulStyle = parent.ulStyle
+		}
+		r.properties |= parent.properties & underline_properties &^ cleared
+	}
+	r.properties |= parent.properties &^ underline_properties &^ cleared
+
+	return r
+}
+
+// Return true if this rendition sets neither colors nor properties
+func (r *rendition) empty() bool {
+	return !r.fg.set() && !r.bg.set() && r.properties == 0 && r.ulStyle == UnderlineNone && !r.ulColor.set()
+}
+
+// Return true if this rendition changes nothing when it is nested in another
+// one, i.e., if it is empty and clears no properties
+func (r *rendition) neutral() bool {
+	return r.empty() && r.cleared == 0
+}
+
+// Append to the byte slice the ANSI escape sequence that activates the colors
+// and properties of this rendition. Nothing is appended if the rendition is
+// empty, since the sequence would reset all colors and properties otherwise
+func (r *rendition) appendSGR(b []byte) []byte {
+
+	if r.empty() {
+		return b
+	}
+
+	// Codes are separated with semicolons
+	b = append(b, prefix...)
+	sep := false
+	if r.fg.set() {
+		b = appendTermColor(b, r.fg, false)
+		sep = true
+	}
+	if r.bg.set() {
+		if sep {
+			b = append(b, ';')
+		}
+		b = appendTermColor(b, r.bg, true)
+		sep = true
+	}
+
+	// Process all properties one by one. Plain underlines are not shown if
+	// they are given with a style
+	var idx uint16
+	for idx = BOLD; idx <= SUBSCRIPT; idx <<= 1 {
+		if r.properties&idx != 0 && (idx != UNDERLINE || r.ulStyle == UnderlineNone) {
+			if sep {
+				b = append(b, ';')
+			}
+			b = append(b, propertyPrefix[idx]...)
+			sep = true
+		}
+	}
+
+	// Finally, the style and color of underlines
+	if r.ulStyle != UnderlineNone {
+		if sep {
+			b = append(b, ';')
+		}
+		b = append(b, underline_style_prefix...)
+		b = strconv.AppendUint(b, uint64(r.ulStyle), 10)
+		sep = true
+	}
+	if r.ulColor.set() {
+		if sep {
+			b = append(b, ';')
+		}
+		b = appendUnderlineColor(b, r.ulColor)
+	}
+
+	return append(b, 'm')
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
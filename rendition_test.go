@@ -0,0 +1,60 @@
+// -*- coding: utf-8 -*-
+// rendition_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 13:05:47.392018554 (1792242347)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import "testing"
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Only the attributes which change are issued, using the codes which unset
+// them, unless resetting all attributes is shorter
+func TestAppendTransition(t *testing.T) {
+
+	red, green := rgbTermColor(Color{R: 0xff}), rgbTermColor(Color{G: 0xff})
+	for _, test := range []struct {
+		name     string
+		from, to rendition
+		want     string
+	}{
+		{"same", rendition{fg: red, properties: BOLD}, rendition{fg: red, properties: BOLD}, ""},
+		{"to empty", rendition{fg: red}, rendition{}, "\x1b[0m"},
+		{"from empty", rendition{}, rendition{fg: red}, "\x1b[38;2;255;0;0m"},
+		{"default", rendition{fg: red}, rendition{fg: red, bg: rgbTermColor(Default)}, ""},
+		{"foreground", rendition{fg: red, properties: BOLD}, rendition{fg: green, properties: BOLD}, "\x1b[38;2;0;255;0m"},
+
+		// Attributes which are not shown anymore are unset
+		{"no bold", rendition{fg: red, properties: BOLD}, rendition{fg: red}, "\x1b[22m"},
+		{"no bold but dim", rendition{fg: red, properties: BOLD | DIM}, rendition{fg: red, properties: DIM}, "\x1b[22;2m"},
+		{"no italic", rendition{fg: red, properties: ITALIC}, rendition{fg: red}, "\x1b[23m"},
+		{"no underline", rendition{fg: red, properties: UNDERLINE}, rendition{fg: red}, "\x1b[24m"},
+		{"no curly underline", rendition{fg: red, ulStyle: UnderlineCurly}, rendition{fg: red}, "\x1b[24m"},
+		{"no foreground", rendition{fg: red, properties: BOLD}, rendition{properties: BOLD}, "\x1b[39m"},
+		{"no background", rendition{fg: red, bg: green}, rendition{fg: red}, "\x1b[49m"},
+		{"no underline color", rendition{fg: red, properties: UNDERLINE, ulColor: green}, rendition{fg: red, properties: UNDERLINE}, "\x1b[59m"},
+
+		// Underlines are changed only if they are shown differently
+		{"underline style", rendition{fg: red, ulStyle: UnderlineCurly}, rendition{fg: red, ulStyle: UnderlineDotted}, "\x1b[4:4m"},
+		{"single underline", rendition{fg: red, properties: UNDERLINE}, rendition{fg: red, ulStyle: UnderlineSingle}, ""},
+		{"double underline", rendition{fg: red, properties: UNDERLINE}, rendition{fg: red, properties: DOUBLE_UNDERLINE}, "\x1b[21m"},
+		{"plain underline", rendition{fg: red, ulStyle: UnderlineCurly}, rendition{fg: red, properties: UNDERLINE}, "\x1b[4m"},
+
+		// Resetting all attributes is shorter
+		{"reset", rendition{fg: red, bg: green, properties: BOLD | ITALIC | UNDERLINE}, rendition{fg: ansi16TermColor(AnsiRed)}, "\x1b[0m\x1b[31m"},
+	} {
+		if got := string(appendTransition(nil, test.from, test.to)); got != test.want {
+			t.Errorf("%s: appendTransition(%+v, %+v) = %q, want %q", test.name, test.from, test.to, got, test.want)
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,242 @@
+// -*- coding: utf-8 -*-
+// spec.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 01:41:07.503862119 (1792201267)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following words have a special meaning in textual color specifications.
+// The first one separates the foreground color from the background color, the
+// second one stands for no color at all (as in git), and the last one is the
+// prefix used for unsetting properties
+const (
+	background_word = "on"
+	normal_word     = "normal"
+	negation_prefix = "no"
+)
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Names of the properties which can be used in textual color specifications.
+// They include the names used by git for the same properties
+var propertyNames = map[string]uint16{
+	"bold":             BOLD,
+	"dim":              DIM,
+	"italic":           ITALIC,
+	"underline":        UNDERLINE,
+	"ul":               UNDERLINE,
+	"blink":            SLOW_BLINK,
+	"slow_blink":       SLOW_BLINK,
+	"rapid_blink":      RAPID_BLINK,
+	"strike":           CROSSED_OUT,
+	"crossed_out":      CROSSED_OUT,
+	"reverse":          REVERSE,
+	"conceal":          CONCEAL,
+	"hidden":           CONCEAL,
+	"double_underline": DOUBLE_UNDERLINE,
+	"double_ul":        DOUBLE_UNDERLINE,
+	"overline":         OVERLINE,
+	"framed":           FRAMED,
+	"encircled":        ENCIRCLED,
+	"superscript":      SUPERSCRIPT,
+	"subscript":        SUBSCRIPT,
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the words of a textual color specification, which are separated by
+// blanks unless they are enclosed in parentheses, e.g., "rgb(255 136 0)"
+func specWords(spec string) (words []string) {
+
+	depth, start := 0, -1
+	for i, r := range spec {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && unicode.IsSpace(r):
+			if start >= 0 {
+				words = append(words, spec[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, spec[start:])
+	}
+
+	return
+}
+
+// Return the color given in the given word, which might be written in any
+// notation accepted by ParseColor, as the name of a bright color of the basic
+// ANSI palette, e.g., brightred, or as the index of a color of the extended
+// ANSI palette, i.e., a number between 0 and 255, as in git
+func parseColorWord(word string) (termColor, error) {
+
+	if c, ok := ansi16Names[word]; ok {
+		return ansi16TermColor(c), nil
+	}
+	if idx, err := strconv.ParseUint(word, 10, 8); err == nil {
+		return ansi256TermColor(Ansi256(idx)), nil
+	}
+	c, err := ParseColor(word)
+	if err != nil {
+		return termColor{}, err
+	}
+
+	return rgbTermColor(c), nil
+}
+
+// Return the property named by the given word, if any, and whether it has to
+// be set or unset, i.e., whether the word is prefixed with "no" or "no-", as in
+// git, e.g., nobold or no-ul
+func parseProperty(word string) (prop uint16, set, ok bool) {
+
+	if prop, ok = propertyNames[word]; ok {
+		return prop, true, true
+	}
+	if rest, found := strings.CutPrefix(word, negation_prefix); found {
+		prop, ok = propertyNames[strings.TrimPrefix(rest, "-")]
+		return prop, false, ok
+	}
+
+	return 0, false, false
+}
+
+// Return the rendition of a textual color specification such as "bold red on
+// #102030", i.e., a list of words separated by blanks which are either the
+// names of properties, styles of underlines or colors (see parseColorWord). As
+// in git, the first color is the foreground color, and the second one is the
+// background color, though the latter can also be given after the word "on".
+// The color "normal" leaves the corresponding color unset, e.g., "normal navy"
+// sets only the background color. Properties can be unset by prefixing them
+// with "no", e.g., "nobold", either if they were given before or in the
+// enclosing color verbs. Words are not case sensitive. It returns an error
+// wrapping ErrInvalidSpec which describes the first word which is not
+// understood
+func parseSpec(spec string) (r rendition, err error) {
+
+	// fgGiven and bgGiven are true once the foreground and background colors
+	// are given, even if they are "normal"
+	var background, fgGiven, bgGiven bool
+	for _, word := range specWords(strings.ToLower(spec)) {
+
+		if prop, set, ok := parseProperty(word); ok {
+			if set {
+				r.properties |= prop
+				r.cleared &^= prop
+			} else {
+				r.properties &^= prop
+				r.cleared |= prop
+			}
+			continue
+		}
+		if style, ok := underlineStyleNames[word]; ok {
+			r.ulStyle = style
+			continue
+		}
+
+		// "on" can be given only once, and only if the background color has
+		// not been given yet
+		if word == background_word {
+			if background || bgGiven {
+				return rendition{}, fmt.Errorf("%w: unexpected %q", ErrInvalidSpec, word)
+			}
+			background = true
+			continue
+		}
+
+		// Otherwise, this must be a color
+		var c termColor
+		if word != normal_word {
+			if c, err = parseColorWord(word); err != nil {
+				return rendition{}, fmt.Errorf("%w: unknown word %q", ErrInvalidSpec, word)
+			}
+		}
+		switch {
+		case !background && !fgGiven:
+			fgGiven = true
+			r.fg = c
+		case !bgGiven:
+			bgGiven = true
+			r.bg = c
+		default:
+			return rendition{}, fmt.Errorf("%w: too many colors: %q", ErrInvalidSpec, word)
+		}
+	}
+
+	// "on" must be followed by a color
+	if background && !bgGiven {
+		return rendition{}, fmt.Errorf("%w: missing background color", ErrInvalidSpec)
+	}
+
+	return
+}
+
+// ParseEffect returns the effect described in the given textual color
+// specification, e.g., "bold ul #ff8800 on navy", as a [Style], which can be
+// given to color verbs and changed with its methods. Specifications consist of
+// a list of words separated by blanks, which are not case sensitive:
+//
+//   - Properties, using the same vocabulary as git: bold, dim, italic, ul (or
+//     underline), blink, strike, reverse and hidden. Other properties are
+//     rapid_blink, double_ul, overline, framed, encircled, superscript and
+//     subscript. They can be prefixed with "no" or "no-" for unsetting them
+//     if they were given before or in the enclosing color verbs, e.g.,
+//     nobold. As in git, "noul" unsets underlines of any style
+//   - Styles of underlines: curly, dotted and dashed (or curly_ul, dotted_ul
+//     and dashed_ul). See [UnderlineStyle]
+//   - Colors written in any notation accepted by [ParseColor], including
+//     "default" for the color used by default by the terminal, or "normal"
+//     for no color at all. As in git, the first color is the foreground color
+//     and the second one is the background color, which can also be preceded
+//     by "on", e.g., "on navy" sets only the background color
+//   - As in git, colors can also be given as the index of a color of the
+//     extended ANSI palette, i.e., a number between 0 and 255, or as the
+//     name of a bright color of the basic ANSI palette, e.g., brightred,
+//     which are shown with the codes of the ANSI palettes
+//
+// As with any other style, the colors which are not given in the
+// specification are left unchanged when it is shown, e.g., those of the
+// enclosing color verbs, unless they are set with the methods of the style:
+//
+//	warning, err := golor.ParseEffect("bold ul")
+//	warning = warning.Fg(golor.DarkOrange)
+//
+// It returns an error wrapping ErrInvalidSpec describing the first word which
+// is not understood
+func ParseEffect(spec string) (Style, error) {
+
+	r, err := parseSpec(spec)
+	if err != nil {
+		return Style{}, fmt.Errorf("golor: %w", err)
+	}
+
+	return Style{r: r}, nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
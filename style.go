@@ -0,0 +1,271 @@
+// -*- coding: utf-8 -*-
+// style.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 03:05:12.224809133 (1792206312)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A Style is a combination of foreground and background colors and properties
+// built with chainable methods, e.g.:
+//
+//	warning := golor.NewStyle().Fg(golor.Color{R: 0xff, G: 0x88}).Bold()
+//
+// Styles can also be parsed from textual color specifications with
+// [ParseEffect]. Styles are values, so that every method returns a new style
+// and the original one is never modified. Styles can be given as arguments to
+// color verbs, as any other color specification. The zero value is a valid
+// style which sets neither colors nor properties, and colors which are not set
+// are left unchanged when the style is shown
+type Style struct {
+	r rendition
+}
+
+// A StyledValue is a value shown with a color specification when it is
+// formatted with the functions of the fmt package. It is created with
+// [Styled]
+type StyledValue struct {
+	value any
+	r     rendition
+	err   error
+	arg   any
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Styled returns a value which is formatted as the given one, but shown with
+// the given effect, which can be any color specification accepted by color
+// verbs. It implements fmt.Formatter, so that values can be colored also when
+// they are given to the functions of the fmt package, e.g.:
+//
+//	fmt.Printf("%-12v|\n", golor.Styled(name, red))
+//
+// Widths are measured in visible runes, i.e., ignoring the escape sequences,
+// and the padding is not colored
+func Styled(v any, effect any) StyledValue {
+
+	r, err := effectRendition(effect)
+	return StyledValue{value: v, r: r, err: err, arg: effect}
+}
+
+// Return a new style which sets neither colors nor properties
+func NewStyle() Style {
+	return Style{}
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return a copy of this style with the given foreground color
+func (s Style) Fg(c Color) Style {
+	s.r.fg = rgbTermColor(c)
+	return s
+}
+
+// Return a copy of this style with the given background color
+func (s Style) Bg(c Color) Style {
+	s.r.bg = rgbTermColor(c)
+	return s
+}
+
+// Return a copy of this style with the given properties, e.g., BOLD|ITALIC,
+// added to those already set
+func (s Style) Properties(properties uint16) Style {
+	s.r.properties |= properties
+	return s
+}
+
+// Return a copy of this style in bold typeface
+func (s Style) Bold() Style {
+	return s.Properties(BOLD)
+}
+
+// Return a copy of this style with a decreased intensity
+func (s Style) Dim() Style {
+	return s.Properties(DIM)
+}
+
+// Return a copy of this style in italics
+func (s Style) Italic() Style {
+	return s.Properties(ITALIC)
+}
+
+// Return a copy of this style underlined
+func (s Style) Underline() Style {
+	return s.Properties(UNDERLINE)
+}
+
+// Return a copy of this style blinking slowly
+func (s Style) SlowBlink() Style {
+	return s.Properties(SLOW_BLINK)
+}
+
+// Return a copy of this style blinking rapidly
+func (s Style) RapidBlink() Style {
+	return s.Properties(RAPID_BLINK)
+}
+
+// Return a copy of this style crossed out
+func (s Style) CrossedOut() Style {
+	return s.Properties(CROSSED_OUT)
+}
+
+// Return a copy of this style underlined with the given style, e.g.,
+// UnderlineCurly, which is shown as a plain underline by terminals which do
+// not support it
+func (s Style) UnderlineStyle(style UnderlineStyle) Style {
+	s.r.ulStyle = style
+	return s
+}
+
+// Return a copy of this style whose underlines are shown with the given color
+// by terminals which support it. Note that it does not underline the text
+func (s Style) UnderlineColor(c Color) Style {
+	s.r.ulColor = rgbTermColor(c)
+	return s
+}
+
+// Return a copy of this style with the foreground and background colors
+// swapped
+func (s Style) Reverse() Style {
+	return s.Properties(REVERSE)
+}
+
+// Return a copy of this style hidden
+func (s Style) Conceal() Style {
+	return s.Properties(CONCEAL)
+}
+
+// Return a copy of this style doubly underlined
+func (s Style) DoubleUnderline() Style {
+	return s.Properties(DOUBLE_UNDERLINE)
+}
+
+// Return a copy of this style overlined
+func (s Style) Overline() Style {
+	return s.Properties(OVERLINE)
+}
+
+// Return a copy of this style framed
+func (s Style) Framed() Style {
+	return s.Properties(FRAMED)
+}
+
+// Return a copy of this style encircled
+func (s Style) Encircled() Style {
+	return s.Properties(ENCIRCLED)
+}
+
+// Return a copy of this style in superscript
+func (s Style) Superscript() Style {
+	return s.Properties(SUPERSCRIPT)
+}
+
+// Return a copy of this style in subscript
+func (s Style) Subscript() Style {
+	return s.Properties(SUBSCRIPT)
+}
+
+// Return the given text shown with this style and the current color profile.
+// The text is shown verbatim, i.e., verbs are not substituted
+func (s Style) Render(text string) string {
+
+	r := s.r.quantize(CurrentColorProfile(), writerUnderlines(nil))
+	if r.empty() {
+		return text
+	}
+
+	b := make([]byte, 0, len(text)+32)
+	b = r.appendSGR(b)
+	b = append(b, text...)
+	b = append(b, suffix...)
+	return string(b)
+}
+
+// Substitute the verbs of the given format string with the given arguments as
+// golor.Sprintf does, and return the resulting string shown with this style.
+// Color verbs in the format string are shown with their own effects, and this
+// style is restored once they end
+func (s Style) Sprintf(format string, a ...any) string {
+
+	p := newPrinter()
+	p.printEffect(s.r, layout{}, parseCached(format), a)
+	p.sync()
+	p.printExtra(a)
+	str := string(p.buf)
+	p.free()
+	return str
+}
+
+// Format the styled value with the given verb, as fmt would do with the value,
+// and add the escape sequences of its effect shown with the current color
+// profile. If the effect is not a color specification, a marker is shown
+// before the value as with color verbs
+func (v StyledValue) Format(f fmt.State, verb rune) {
+
+	r := v.r.quantize(CurrentColorProfile(), writerUnderlines(nil))
+
+	// The value is formatted without the width, so that the padding is added
+	// outside the effect and computed with the visible width. The only
+	// exception is padding with leading zeros, which is part of the number
+	width, hasWidth := f.Width()
+	var directive strings.Builder
+	directive.WriteByte('%')
+	for _, flag := range verb_flags {
+		if f.Flag(int(flag)) {
+			directive.WriteRune(flag)
+		}
+	}
+	zeros := f.Flag('0') && !f.Flag('-')
+	if hasWidth && zeros {
+		fmt.Fprintf(&directive, "%d", width)
+	}
+	if prec, ok := f.Precision(); ok {
+		fmt.Fprintf(&directive, ".%d", prec)
+	}
+	directive.WriteRune(verb)
+
+	b := make([]byte, 0, 64)
+	if spec, ok := v.arg.(string); ok && v.err != nil {
+		b = fmt.Appendf(b, badspec_marker, spec)
+	} else if v.err != nil {
+		b = fmt.Appendf(b, badtype_marker, v.arg)
+	} else {
+		b = r.appendSGR(b)
+	}
+	start := len(b)
+	b = fmt.Appendf(b, directive.String(), v.value)
+	n := visibleWidth(b[start:])
+	if v.err == nil && !r.empty() {
+		b = append(b, suffix...)
+	}
+
+	// Finally, add the padding either to the left or the right
+	var padding []byte
+	if hasWidth && !zeros && n < width {
+		padding = []byte(strings.Repeat(" ", width-n))
+	}
+	if f.Flag('-') {
+		f.Write(b)
+		f.Write(padding)
+		return
+	}
+	f.Write(padding)
+	f.Write(b)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
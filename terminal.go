@@ -0,0 +1,218 @@
+// -*- coding: utf-8 -*-
+// terminal.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 06:21:37.902284116 (1792218097)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"weak"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// A continuous integration service whose logs are shown with colors, which is
+// recognized because the given environment variable is set
+type ciService struct {
+	variable string
+	profile  ColorProfile
+}
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Continuous integration services whose logs are shown with colors, though
+// their output is usually not a terminal. Any other service which just sets
+// the variable CI is assumed to show no colors at all
+var ciServices = []ciService{
+	{variable: "GITHUB_ACTIONS", profile: TrueColor},
+	{variable: "GITEA_ACTIONS", profile: TrueColor},
+	{variable: "GITLAB_CI", profile: ANSI256},
+	{variable: "BUILDKITE", profile: ANSI256},
+	{variable: "CIRCLECI", profile: ANSI16},
+	{variable: "TRAVIS", profile: ANSI16},
+	{variable: "APPVEYOR", profile: ANSI16},
+	{variable: "DRONE", profile: ANSI16},
+	{variable: "TEAMCITY_VERSION", profile: ANSI16},
+}
+
+// Terminal programs, as given in TERM_PROGRAM, whose color profile is known
+var termPrograms = map[string]ColorProfile{
+	"iTerm.app":      TrueColor,
+	"WezTerm":        TrueColor,
+	"vscode":         TrueColor,
+	"ghostty":        TrueColor,
+	"Hyper":          TrueColor,
+	"Apple_Terminal": ANSI256,
+}
+
+// Prefixes of the names of the terminals, as given in TERM, which support
+// 24-bit colors though their names do not say so
+var trueColorTerms = []string{"alacritty", "foot", "kitty", "wezterm", "xterm-ghostty", "xterm-kitty"}
+
+// Prefixes of the names of the terminals, as given in TERM, and terminal
+// programs, as given in TERM_PROGRAM, which support styles and colors of
+// underlines
+var (
+	underlineTerms    = []string{"alacritty", "foot", "kitty", "wezterm", "xterm-ghostty", "xterm-kitty"}
+	underlinePrograms = []string{"WezTerm", "ghostty", "iTerm.app", "vscode"}
+)
+
+// Whether the terminal supports styles and colors of underlines, which is
+// detected only once, the first time it is needed
+var envUnderlines = sync.OnceValue(termUnderlines)
+
+// The color profiles of the continuous integration service and the terminal
+// given in the environment variables, which are read only once, the first time
+// they are needed
+var (
+	envCIColorProfile   = sync.OnceValues(ciColorProfile)
+	envTermColorProfile = sync.OnceValues(termColorProfile)
+)
+
+// The color profiles of the standard output and error are detected only once,
+// the first time they are needed
+var (
+	stdoutColorProfile = sync.OnceValue(func() ColorProfile { return DetectColorProfile(os.Stdout) })
+	stderrColorProfile = sync.OnceValue(func() ColorProfile { return DetectColorProfile(os.Stderr) })
+)
+
+// The color profiles of any other files, which are detected only once for
+// every file. Files are referenced weakly, so that they can still be collected,
+// and their profiles are then removed
+var fileColorProfiles sync.Map
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return the color profile of the continuous integration service where the
+// program is running, if any, and whether it was recognized at all
+func ciColorProfile() (ColorProfile, bool) {
+
+	for _, service := range ciServices {
+		if _, ok := os.LookupEnv(service.variable); ok {
+			return service.profile, true
+		}
+	}
+	if _, ok := os.LookupEnv("CI"); ok {
+		return Ascii, true
+	}
+
+	return Ascii, false
+}
+
+// Return the color profile of the terminal described in the environment
+// variables TERM, COLORTERM and TERM_PROGRAM, and whether it is described at
+// all
+func termColorProfile() (ColorProfile, bool) {
+
+	term := strings.ToLower(os.Getenv("TERM"))
+	if term == "dumb" {
+		return Ascii, true
+	}
+	if colorterm := strings.ToLower(os.Getenv("COLORTERM")); colorterm == "truecolor" || colorterm == "24bit" {
+		return TrueColor, true
+	}
+	if profile, ok := termPrograms[os.Getenv("TERM_PROGRAM")]; ok {
+		return profile, true
+	}
+
+	switch {
+	case term == "":
+		return Ascii, false
+	case strings.HasSuffix(term, "-direct") || strings.Contains(term, "truecolor") || strings.Contains(term, "24bit"):
+		return TrueColor, true
+	case strings.Contains(term, "256color"):
+		return ANSI256, true
+	}
+	for _, prefix := range trueColorTerms {
+		if strings.HasPrefix(term, prefix) {
+			return TrueColor, true
+		}
+	}
+
+	// Any other terminal is assumed to support at least the basic palette
+	return ANSI16, true
+}
+
+// Return whether the terminal described in the environment variables TERM,
+// TERM_PROGRAM and VTE_VERSION supports styles and colors of underlines. VTE
+// supports them since its version 0.52
+func termUnderlines() bool {
+
+	if slices.Contains(underlinePrograms, os.Getenv("TERM_PROGRAM")) {
+		return true
+	}
+	if version, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && version >= 5200 {
+		return true
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	return slices.ContainsFunc(underlineTerms, func(prefix string) bool {
+		return strings.HasPrefix(term, prefix)
+	})
+}
+
+// Return the color profile of the given file, which is detected only the first
+// time it is needed
+func fileColorProfile(f *os.File) ColorProfile {
+
+	if f == nil {
+		return DetectColorProfile(f)
+	}
+	key := weak.Make(f)
+	if profile, ok := fileColorProfiles.Load(key); ok {
+		return profile.(ColorProfile)
+	}
+	profile := DetectColorProfile(f)
+	if _, loaded := fileColorProfiles.LoadOrStore(key, profile); !loaded {
+		runtime.AddCleanup(f, func(key weak.Pointer[os.File]) { fileColorProfiles.Delete(key) }, key)
+	}
+	return profile
+}
+
+// DetectColorProfile returns the color profile supported by the given file. If
+// it is a terminal, the profile is chosen after the environment variables
+// TERM, COLORTERM and TERM_PROGRAM. Otherwise, e.g., if it is a regular file
+// or a pipe, no colors are supported unless the program is running in a
+// continuous integration service whose logs are shown with colors, such as
+// GitHub Actions or GitLab CI. Terminals are recognized in Linux, macOS and
+// the BSDs. Elsewhere, e.g., in Windows, every file is taken as a terminal
+// which supports 24-bit colors unless the environment says otherwise. The
+// environment variables are read only once, the first time a profile is
+// detected
+func DetectColorProfile(f *os.File) ColorProfile {
+
+	ci, isCI := envCIColorProfile()
+	if !isTerminal(f) {
+		return ci
+	}
+	profile, ok := envTermColorProfile()
+	switch {
+	case ok:
+		return profile
+	case isCI:
+
+		// Terminals in continuous integration services might not be
+		// described
+		return ci
+	case !terminal_detection:
+		return TrueColor
+	}
+
+	return profile
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
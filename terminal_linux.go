@@ -0,0 +1,53 @@
+// -*- coding: utf-8 -*-
+// terminal_linux.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 06:34:02.118943650 (1792218842)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// Terminals are recognized in Linux
+const terminal_detection = true
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Return whether the given file is a terminal, i.e., whether the attributes of
+// a terminal can be retrieved with the ioctl TCGETS. The file descriptor is
+// accessed through its raw connection so that it is not set in blocking mode
+func isTerminal(f *os.File) bool {
+
+	if f == nil {
+		return false
+	}
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var errno syscall.Errno
+	if err = conn.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	}); err != nil {
+		return false
+	}
+
+	return errno == 0
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
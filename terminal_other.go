@@ -0,0 +1,34 @@
+// -*- coding: utf-8 -*-
+// terminal_other.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 06:35:48.406122597 (1792218948)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package golor
+
+import "os"
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// Terminals can not be recognized in other systems
+const terminal_detection = false
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Terminals can not be recognized in this system, and thus every file is taken
+// as a terminal, so that escape sequences are issued as long as the
+// environment does not say otherwise
+func isTerminal(f *os.File) bool {
+	return f != nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
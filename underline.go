@@ -0,0 +1,110 @@
+// -*- coding: utf-8 -*-
+// underline.go
+// -----------------------------------------------------------------------------
+//
+// Started on <sáb 17-10-2026 08:14:52.036215890 (1792224892)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package golor
+
+import "strconv"
+
+// Constants
+// ----------------------------------------------------------------------------
+
+// The following constants are the styles of underlines supported by modern
+// terminals, e.g., kitty, WezTerm, foot or those based on VTE. They are shown
+// with the codes 4:1 to 4:5
+const (
+	UnderlineNone   UnderlineStyle = iota // no style, see the property UNDERLINE
+	UnderlineSingle                       // a single straight line
+	UnderlineDouble                       // two straight lines
+	UnderlineCurly                        // a wavy line, as used by spell checkers
+	UnderlineDotted                       // a dotted line
+	UnderlineDashed                       // a dashed line
+)
+
+// The following constants define the codes used for showing the style and
+// color of underlines
+const (
+	underline_style_prefix   = "4:"
+	underline_color_prefix   = "58;2"
+	underline_ansi256_prefix = "58;5"
+	default_underline_color  = "59"
+)
+
+// Types
+// ----------------------------------------------------------------------------
+
+// The style of an underline. Styles are shown only by terminals which support
+// them, and as plain underlines otherwise
+type UnderlineStyle uint8
+
+// Variables
+// ----------------------------------------------------------------------------
+
+// Names of the styles of underlines which can be used in textual color
+// specifications
+var underlineStyleNames = map[string]UnderlineStyle{
+	"curly":     UnderlineCurly,
+	"curly_ul":  UnderlineCurly,
+	"dotted":    UnderlineDotted,
+	"dotted_ul": UnderlineDotted,
+	"dashed":    UnderlineDashed,
+	"dashed_ul": UnderlineDashed,
+}
+
+// Functions
+// ----------------------------------------------------------------------------
+
+// Append to the byte slice the ANSI codes that set the given color as the color
+// of underlines. Colors of the basic ANSI palette are given with their index
+// in the extended palette, since there are no specific codes for them
+func appendUnderlineColor(b []byte, c termColor) []byte {
+
+	switch c.kind {
+	case rgbColor:
+		b = append(b, underline_color_prefix...)
+		b = append(b, ';')
+		return appendColor(b, c.rgb)
+
+	case ansi16Color, ansi256Color:
+		b = append(b, underline_ansi256_prefix...)
+		b = append(b, ';')
+		return strconv.AppendUint(b, uint64(c.index), 10)
+
+	case defaultColor:
+		return append(b, default_underline_color...)
+	}
+
+	return b
+}
+
+// Methods
+// ----------------------------------------------------------------------------
+
+// Return the name of the style of underlines
+func (style UnderlineStyle) String() string {
+
+	switch style {
+	case UnderlineNone:
+		return "none"
+	case UnderlineSingle:
+		return "single"
+	case UnderlineDouble:
+		return "double"
+	case UnderlineCurly:
+		return "curly"
+	case UnderlineDotted:
+		return "dotted"
+	case UnderlineDashed:
+		return "dashed"
+	}
+	return "unknown"
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
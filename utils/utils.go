@@ -58,9 +58,8 @@ func RgbToHsl(r, g, b uint8) (h, s, l float64) {
 }
 
 // Given the Hue, Saturation and Lightness of a combination of red, green and
-// blue, return its three primary colors as bytes
-func HslToRgb(h, s, l float64) (r, g, b uint8) {
-	var rF, gF, bF float64
+// blue, return its three primary colors in the range [0, 1]
+func HslToRgbFloat(h, s, l float64) (rF, gF, bF float64) {
 
 	if s == 0 {
 		rF, gF, bF = l, l, l
@@ -94,9 +93,77 @@ func HslToRgb(h, s, l float64) (r, g, b uint8) {
 		bF = hue2rgb(p, q, h-1.0/3)
 	}
 
+	return
+}
+
+// Given the Hue, Saturation and Lightness of a combination of red, green and
+// blue, return its three primary colors as bytes
+func HslToRgb(h, s, l float64) (r, g, b uint8) {
+
+	rF, gF, bF := HslToRgbFloat(h, s, l)
 	return uint8(rF * 0xff), uint8(gF * 0xff), uint8(bF * 0xff)
 }
 
+// Convert a component of a color in the sRGB space, in the range [0, 1], to
+// the linear RGB space
+func toLinear(c float64) float64 {
+
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// Convert a component of a color in the linear RGB space to the sRGB space,
+// clamping it to the range [0, 0xff]
+func fromLinear(c float64) uint8 {
+
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(math.Max(0, math.Min(1, c)) * 0xff))
+}
+
+// Given three bytes with R, G and B values, return the Lightness and the a and
+// b components of its combination in the Oklab color space. Oklab is
+// perceptually uniform, so that the euclidean distance between colors in this
+// space is a good measure of how different they look
+func RgbToOklab(r, g, b uint8) (l, a, bb float64) {
+
+	rf := toLinear(float64(r) / 0xff)
+	gf := toLinear(float64(g) / 0xff)
+	bf := toLinear(float64(b) / 0xff)
+
+	lc := math.Cbrt(0.4122214708*rf + 0.5363325363*gf + 0.0514459929*bf)
+	mc := math.Cbrt(0.2119034982*rf + 0.6806995451*gf + 0.1073969566*bf)
+	sc := math.Cbrt(0.0883024619*rf + 0.2817188376*gf + 0.6299787005*bf)
+
+	l = 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc
+	a = 1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc
+	bb = 0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+	return
+}
+
+// Given the Lightness and the a and b components of a color in the Oklab color
+// space, return its three primary colors as bytes. Colors out of the sRGB gamut
+// are clamped
+func OklabToRgb(l, a, bb float64) (r, g, b uint8) {
+
+	lc := l + 0.3963377774*a + 0.2158037573*bb
+	mc := l - 0.1055613458*a - 0.0638541728*bb
+	sc := l - 0.0894841775*a - 1.2914855480*bb
+
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+
+	r = fromLinear(4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc)
+	g = fromLinear(-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc)
+	b = fromLinear(-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc)
+	return
+}
+
 // Use the HSL model to create a pleasant gradient of color with the given
 // number of steps from the start to the specified end. Note that the start and
 // end consist of a combination of red, green and blue and thus, they are given